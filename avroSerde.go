@@ -0,0 +1,134 @@
+package srclient
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// AvroSerializer serializes records using the Avro
+// binary encoding, framed with the wire format used
+// by Confluent's serializers: the magic byte, the
+// 4-byte big-endian schema ID and the Avro payload.
+type AvroSerializer struct {
+	resolver  SchemaResolver
+	codecs    *avroCodecCache
+	magicByte byte
+}
+
+// NewAvroSerializer creates a serializer that encodes
+// records with the schema returned by the resolver.
+func NewAvroSerializer(resolver SchemaResolver) *AvroSerializer {
+	return &AvroSerializer{
+		resolver:  resolver,
+		codecs:    newAvroCodecCache(),
+		magicByte: defaultMagicByte,
+	}
+}
+
+// SetMagicByte sets the first byte of the records, which is 0 in the wire format
+// used by Confluent's serializers, to interoperate with frameworks using another one.
+func (serializer *AvroSerializer) SetMagicByte(magicByte byte) {
+	serializer.magicByte = magicByte
+}
+
+// Serialize encodes the native Go value, as accepted by goavro,
+// with the schema resolved for the topic.
+func (serializer *AvroSerializer) Serialize(topic string, native interface{}) ([]byte, error) {
+	schema, err := serializer.resolver.ResolveSchema(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, ok := serializer.codecs.get(schema.ID())
+	if !ok {
+		codec, err = serializer.codecs.add(schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return codec.BinaryFromNative(encodeHeader(serializer.magicByte, schema.ID()), native)
+}
+
+// AvroDeserializer deserializes records framed with
+// the wire format used by Confluent's serializers,
+// fetching the schema from the ID in their header.
+type AvroDeserializer struct {
+	client    ISchemaRegistryClient
+	codecs    *avroCodecCache
+	magicByte byte
+}
+
+// NewAvroDeserializer creates a deserializer that fetches
+// the schemas of the records it decodes from the client.
+func NewAvroDeserializer(client ISchemaRegistryClient) *AvroDeserializer {
+	return &AvroDeserializer{
+		client:    client,
+		codecs:    newAvroCodecCache(),
+		magicByte: defaultMagicByte,
+	}
+}
+
+// SetMagicByte sets the first byte records are expected to start with, which is 0 in
+// the wire format used by Confluent's serializers. Other records are rejected.
+func (deserializer *AvroDeserializer) SetMagicByte(magicByte byte) {
+	deserializer.magicByte = magicByte
+}
+
+// Deserialize decodes the record into the native Go value goavro produces.
+func (deserializer *AvroDeserializer) Deserialize(data []byte) (interface{}, error) {
+	schemaID, payload, err := decodeHeader(deserializer.magicByte, data)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, ok := deserializer.codecs.get(schemaID)
+	if !ok {
+		schema, err := deserializer.client.GetSchema(schemaID)
+		if err != nil {
+			return nil, err
+		}
+		codec, err = deserializer.codecs.add(schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	native, _, err := codec.NativeFromBinary(payload)
+	return native, err
+}
+
+// avroCodecCache holds the codecs compiled
+// for Avro schemas, keyed by their ID.
+type avroCodecCache struct {
+	codecs     map[int]*goavro.Codec
+	codecsLock sync.RWMutex
+}
+
+func newAvroCodecCache() *avroCodecCache {
+	return &avroCodecCache{codecs: make(map[int]*goavro.Codec)}
+}
+
+func (cache *avroCodecCache) get(schemaID int) (*goavro.Codec, bool) {
+	cache.codecsLock.RLock()
+	defer cache.codecsLock.RUnlock()
+	codec, ok := cache.codecs[schemaID]
+	return codec, ok
+}
+
+func (cache *avroCodecCache) add(schema *Schema) (*goavro.Codec, error) {
+	if schema.SchemaType() != nil && *schema.SchemaType() != Avro {
+		return nil, fmt.Errorf("schema %d is not an Avro schema", schema.ID())
+	}
+	codec, err := goavro.NewCodec(schema.Schema())
+	if err != nil {
+		return nil, err
+	}
+
+	cache.codecsLock.Lock()
+	defer cache.codecsLock.Unlock()
+	cache.codecs[schema.ID()] = codec
+	return codec, nil
+}
@@ -0,0 +1,156 @@
+package srclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvroSerializer_RoundTrip(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	schema, err := registry.CreateSchema("cupcakes-value", testSchema1, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serializer := NewAvroSerializer(NewTopicNameSchemaResolver(registry, ValueSerde))
+	deserializer := NewAvroDeserializer(registry)
+
+	// Act
+	data, err := serializer.Serialize("cupcakes", map[string]interface{}{"flavor": "vanilla"})
+	assert.NoError(t, err)
+	native, err := deserializer.Deserialize(data)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, byte(schema.ID())}, data[:wireHeaderSize])
+	assert.Equal(t, map[string]interface{}{"flavor": "vanilla"}, native)
+}
+
+func TestAvroSerializer_RoundTripWithMagicByte(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	schema, err := registry.CreateSchema("cupcakes-value", testSchema1, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serializer := NewAvroSerializer(NewTopicNameSchemaResolver(registry, ValueSerde))
+	serializer.SetMagicByte(2)
+	deserializer := NewAvroDeserializer(registry)
+	deserializer.SetMagicByte(2)
+	confluentDeserializer := NewAvroDeserializer(registry)
+
+	// Act
+	data, err := serializer.Serialize("cupcakes", map[string]interface{}{"flavor": "vanilla"})
+	assert.NoError(t, err)
+	native, err := deserializer.Deserialize(data)
+	_, confluentErr := confluentDeserializer.Deserialize(data)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{2, 0, 0, 0, byte(schema.ID())}, data[:wireHeaderSize])
+	assert.Equal(t, map[string]interface{}{"flavor": "vanilla"}, native)
+	assert.ErrorIs(t, confluentErr, ErrInvalidMagicByte)
+}
+
+func TestAvroSerializer_ResolvesKeySubject(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	_, err := registry.CreateSchema("cupcakes-value", testSchema1, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keySchema, err := registry.CreateSchema("cupcakes-key", `"string"`, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serializer := NewAvroSerializer(NewTopicNameSchemaResolver(registry, KeySerde))
+
+	// Act
+	data, err := serializer.Serialize("cupcakes", "key")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, byte(keySchema.ID()), data[wireHeaderSize-1])
+}
+
+func TestAvroSerializer_ReturnsErrorOnMissingSubjectWithNotFoundAsNil(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+		rw.Write([]byte(`{"error_code":40401,"message":"Subject 'cupcakes-value' not found."}`))
+	}))
+	defer server.Close()
+	registry := NewSchemaRegistryClient(server.URL, WithNotFoundAsNil())
+	serializer := NewAvroSerializer(NewTopicNameSchemaResolver(registry, ValueSerde))
+
+	// Act
+	data, err := serializer.Serialize("cupcakes", map[string]interface{}{"flavor": "vanilla"})
+
+	// Assert
+	assert.Nil(t, data)
+	assert.ErrorIs(t, err, ErrSubjectNotFound)
+}
+
+func TestAvroDeserializer_CachesCodecsBySchemaID(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	_, err := registry.CreateSchema("cupcakes-value", testSchema1, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serializer := NewAvroSerializer(NewTopicNameSchemaResolver(registry, ValueSerde))
+	deserializer := NewAvroDeserializer(registry)
+	data, err := serializer.Serialize("cupcakes", map[string]interface{}{"flavor": "vanilla"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = deserializer.Deserialize(data)
+	assert.NoError(t, err)
+
+	// Act
+	// The schema can't be fetched anymore, so only the cached codec can decode the record
+	registry.schemaIDs = map[int]*Schema{}
+	native, err := deserializer.Deserialize(data)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"flavor": "vanilla"}, native)
+}
+
+func TestAvroDeserializer_ReturnsErrorOnInvalidHeader(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		data          []byte
+		expectedError error
+	}{
+		"too short": {
+			data:          []byte{0, 0, 0},
+			expectedError: ErrMessageTooShort,
+		},
+		"invalid magic byte": {
+			data:          []byte{1, 0, 0, 0, 1, 2},
+			expectedError: ErrInvalidMagicByte,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			deserializer := NewAvroDeserializer(CreateMockSchemaRegistryClient("http://localhost:8081"))
+
+			native, err := deserializer.Deserialize(testData.data)
+
+			assert.Nil(t, native)
+			assert.ErrorIs(t, err, testData.expectedError)
+		})
+	}
+}
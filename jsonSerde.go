@@ -0,0 +1,171 @@
+package srclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var (
+	// ErrSchemaViolation is returned when validation is enabled and
+	// a payload doesn't conform to its JSON schema.
+	ErrSchemaViolation = errors.New("payload does not conform to the schema")
+	// ErrInvalidJsonSchema is returned when validation is enabled and
+	// the schema can't be compiled as a JSON schema.
+	ErrInvalidJsonSchema = errors.New("schema is not a valid JSON schema")
+)
+
+// JsonSerializer serializes records as UTF-8 JSON,
+// framed with the wire format used by Confluent's
+// serializers: the magic byte, the 4-byte big-endian
+// schema ID and the JSON payload.
+type JsonSerializer struct {
+	resolver  SchemaResolver
+	validate  bool
+	schemas   *jsonSchemaCache
+	magicByte byte
+}
+
+// NewJsonSerializer creates a serializer that frames records with the ID of
+// the schema returned by the resolver. If validate is set to true, records
+// are validated against the schema before being serialized.
+func NewJsonSerializer(resolver SchemaResolver, validate bool) *JsonSerializer {
+	return &JsonSerializer{
+		resolver:  resolver,
+		validate:  validate,
+		schemas:   newJsonSchemaCache(),
+		magicByte: defaultMagicByte,
+	}
+}
+
+// SetMagicByte sets the first byte of the records, which is 0 in the wire format
+// used by Confluent's serializers, to interoperate with frameworks using another one.
+func (serializer *JsonSerializer) SetMagicByte(magicByte byte) {
+	serializer.magicByte = magicByte
+}
+
+// Serialize encodes the value as JSON with the schema resolved for the topic.
+func (serializer *JsonSerializer) Serialize(topic string, value interface{}) ([]byte, error) {
+	schema, err := serializer.resolver.ResolveSchema(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if serializer.validate {
+		jsonSchema, ok := serializer.schemas.get(schema.ID())
+		if !ok {
+			jsonSchema, err = serializer.schemas.add(schema)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := validateJson(jsonSchema, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(encodeHeader(serializer.magicByte, schema.ID()), payload...), nil
+}
+
+// JsonDeserializer deserializes records framed with
+// the wire format used by Confluent's serializers.
+type JsonDeserializer struct {
+	client    ISchemaRegistryClient
+	validate  bool
+	schemas   *jsonSchemaCache
+	magicByte byte
+}
+
+// NewJsonDeserializer creates a deserializer for JSON records. If validate is set
+// to true, records are validated against the schema found in their header, which
+// is fetched from the client.
+func NewJsonDeserializer(client ISchemaRegistryClient, validate bool) *JsonDeserializer {
+	return &JsonDeserializer{
+		client:    client,
+		validate:  validate,
+		schemas:   newJsonSchemaCache(),
+		magicByte: defaultMagicByte,
+	}
+}
+
+// SetMagicByte sets the first byte records are expected to start with, which is 0 in
+// the wire format used by Confluent's serializers. Other records are rejected.
+func (deserializer *JsonDeserializer) SetMagicByte(magicByte byte) {
+	deserializer.magicByte = magicByte
+}
+
+// Deserialize decodes the JSON payload of the record into target.
+func (deserializer *JsonDeserializer) Deserialize(data []byte, target interface{}) error {
+	schemaID, payload, err := decodeHeader(deserializer.magicByte, data)
+	if err != nil {
+		return err
+	}
+
+	if deserializer.validate {
+		jsonSchema, ok := deserializer.schemas.get(schemaID)
+		if !ok {
+			schema, err := deserializer.client.GetSchema(schemaID)
+			if err != nil {
+				return err
+			}
+			jsonSchema, err = deserializer.schemas.add(schema)
+			if err != nil {
+				return err
+			}
+		}
+		if err := validateJson(jsonSchema, payload); err != nil {
+			return err
+		}
+	}
+
+	return json.Unmarshal(payload, target)
+}
+
+// jsonSchemaCache holds the compiled
+// JSON schemas, keyed by their ID.
+type jsonSchemaCache struct {
+	schemas     map[int]*jsonschema.Schema
+	schemasLock sync.RWMutex
+}
+
+func newJsonSchemaCache() *jsonSchemaCache {
+	return &jsonSchemaCache{schemas: make(map[int]*jsonschema.Schema)}
+}
+
+func (cache *jsonSchemaCache) get(schemaID int) (*jsonschema.Schema, bool) {
+	cache.schemasLock.RLock()
+	defer cache.schemasLock.RUnlock()
+	jsonSchema, ok := cache.schemas[schemaID]
+	return jsonSchema, ok
+}
+
+func (cache *jsonSchemaCache) add(schema *Schema) (*jsonschema.Schema, error) {
+	jsonSchema, err := jsonschema.CompileString("schema.json", schema.Schema())
+	if err != nil {
+		return nil, fmt.Errorf("%w: schema %d: %v", ErrInvalidJsonSchema, schema.ID(), err)
+	}
+
+	cache.schemasLock.Lock()
+	defer cache.schemasLock.Unlock()
+	cache.schemas[schema.ID()] = jsonSchema
+	return jsonSchema, nil
+}
+
+func validateJson(jsonSchema *jsonschema.Schema, payload []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(payload, &value); err != nil {
+		return err
+	}
+	if err := jsonSchema.Validate(value); err != nil {
+		return fmt.Errorf("%w: %v", ErrSchemaViolation, err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package srclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const testJsonSchema = `{"type": "object", "properties": {"flavor": {"type": "string"}}, "required": ["flavor"]}`
+
+type cupcake struct {
+	Flavor string `json:"flavor"`
+}
+
+// newJsonMockRegistry registers the JSON schema directly, as the mock only accepts valid Avro schemas
+func newJsonMockRegistry(t *testing.T, subject string, id int) *MockSchemaRegistryClient {
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	schema, err := NewSchema(id, testJsonSchema, Json, 1, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry.schemaVersions[subject] = map[int]*Schema{1: schema}
+	registry.schemaIDs[id] = schema
+	return registry
+}
+
+func TestJsonSerializer_RoundTrip(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		validate bool
+	}{
+		"without validation": {validate: false},
+		"with validation":    {validate: true},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := newJsonMockRegistry(t, "cupcakes-value", 3)
+			serializer := NewJsonSerializer(NewTopicNameSchemaResolver(registry, ValueSerde), testData.validate)
+			deserializer := NewJsonDeserializer(registry, testData.validate)
+
+			// Act
+			data, err := serializer.Serialize("cupcakes", cupcake{Flavor: "vanilla"})
+			assert.NoError(t, err)
+			var result cupcake
+			err = deserializer.Deserialize(data, &result)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, append([]byte{0, 0, 0, 0, 3}, `{"flavor":"vanilla"}`...), data)
+			assert.Equal(t, cupcake{Flavor: "vanilla"}, result)
+		})
+	}
+}
+
+func TestJsonSerializer_RoundTripWithMagicByte(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := newJsonMockRegistry(t, "cupcakes-value", 3)
+	serializer := NewJsonSerializer(NewTopicNameSchemaResolver(registry, ValueSerde), true)
+	serializer.SetMagicByte(1)
+	deserializer := NewJsonDeserializer(registry, true)
+	deserializer.SetMagicByte(1)
+	confluentDeserializer := NewJsonDeserializer(registry, true)
+
+	// Act
+	data, err := serializer.Serialize("cupcakes", cupcake{Flavor: "vanilla"})
+	assert.NoError(t, err)
+	var result cupcake
+	err = deserializer.Deserialize(data, &result)
+	confluentErr := confluentDeserializer.Deserialize(data, &result)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, append([]byte{1, 0, 0, 0, 3}, `{"flavor":"vanilla"}`...), data)
+	assert.Equal(t, cupcake{Flavor: "vanilla"}, result)
+	assert.ErrorIs(t, confluentErr, ErrInvalidMagicByte)
+}
+
+func TestJsonSerializer_ReturnsErrorOnMissingSubjectWithNotFoundAsNil(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNotFound)
+		rw.Write([]byte(`{"error_code":40401,"message":"Subject 'cupcakes-value' not found."}`))
+	}))
+	defer server.Close()
+	registry := NewSchemaRegistryClient(server.URL, WithNotFoundAsNil())
+	serializer := NewJsonSerializer(NewTopicNameSchemaResolver(registry, ValueSerde), false)
+
+	// Act
+	data, err := serializer.Serialize("cupcakes", cupcake{Flavor: "vanilla"})
+
+	// Assert
+	assert.Nil(t, data)
+	assert.ErrorIs(t, err, ErrSubjectNotFound)
+}
+
+func TestJsonSerializer_ReturnsSchemaViolation(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := newJsonMockRegistry(t, "cupcakes-value", 3)
+	serializer := NewJsonSerializer(NewTopicNameSchemaResolver(registry, ValueSerde), true)
+
+	// Act
+	data, err := serializer.Serialize("cupcakes", map[string]interface{}{"flavor": 12})
+
+	// Assert
+	assert.Nil(t, data)
+	assert.ErrorIs(t, err, ErrSchemaViolation)
+}
+
+func TestJsonDeserializer_ReturnsSchemaViolation(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		validate      bool
+		expectedError error
+	}{
+		"without validation": {validate: false},
+		"with validation":    {validate: true, expectedError: ErrSchemaViolation},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := newJsonMockRegistry(t, "cupcakes-value", 3)
+			deserializer := NewJsonDeserializer(registry, testData.validate)
+			data := append([]byte{0, 0, 0, 0, 3}, `{"topping":"sprinkles"}`...)
+
+			// Act
+			var result map[string]interface{}
+			err := deserializer.Deserialize(data, &result)
+
+			// Assert
+			if testData.expectedError != nil {
+				assert.ErrorIs(t, err, testData.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, map[string]interface{}{"topping": "sprinkles"}, result)
+			}
+		})
+	}
+}
+
+func TestJsonDeserializer_ReturnsErrorOnInvalidJsonSchema(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	_, err := registry.CreateSchema("cupcakes-value", `"string"`, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deserializer := NewJsonDeserializer(registry, true)
+
+	// Act
+	var result string
+	err = deserializer.Deserialize(append([]byte{0, 0, 0, 0, 1}, `"vanilla"`...), &result)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidJsonSchema)
+	_, compileErr := jsonschema.CompileString("schema.json", `"string"`)
+	assert.EqualError(t, err, "schema is not a valid JSON schema: schema 1: "+compileErr.Error())
+}
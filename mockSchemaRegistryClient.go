@@ -1,11 +1,16 @@
 package srclient
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/linkedin/goavro/v2"
@@ -18,8 +23,9 @@ var _ ISchemaRegistryClient = new(MockSchemaRegistryClient)
 var (
 	errInvalidSchemaType       = errors.New("invalid schema type. valid values are Avro, Json, or Protobuf")
 	errSchemaAlreadyRegistered = errors.New("schema already registered")
-	errSchemaNotFound          = errors.New("schema not found")
-	errSubjectNotFound         = errors.New("subject not found")
+	errSchemaNotFound          = ErrSchemaNotFound
+	errSubjectNotFound         = ErrSubjectNotFound
+	errCompatibilityNotFound   = ErrCompatibilityNotConfigured
 	errNotImplemented          = errors.New("not implemented")
 )
 
@@ -28,22 +34,48 @@ type MockSchemaRegistryClient struct {
 	// schemaRegistryURL is used to form errors
 	schemaRegistryURL string
 
+	// registryLock guards the fields below, so the mock can be shared across goroutines
+	registryLock sync.RWMutex
+
 	// schemaVersions is a map of subject to a map of versions to the actual schema
 	schemaVersions map[string]map[int]*Schema
 
 	// schemaIDs is a map of schema ID to the actual schema
 	schemaIDs map[int]*Schema
 
+	// deletedVersions is a map of subject to the versions that have been soft deleted
+	deletedVersions map[string]map[int]*Schema
+
 	// idCounter is used to generate unique IDs for each schema
 	idCounter int
+
+	// globalCompatibility is the compatibility level of subjects without their own
+	globalCompatibility CompatibilityLevel
+
+	// globalConfig holds the global settings other than the compatibility level
+	globalConfig RegistryConfig
+
+	// subjectCompatibilities is a map of subject to its own compatibility level
+	subjectCompatibilities map[string]CompatibilityLevel
+
+	// username, password and bearerToken are the last ones set, so tests can assert on them
+	username    string
+	password    string
+	bearerToken string
+
+	// pingErr is returned by Ping, so tests can exercise an unreachable registry
+	pingErr error
 }
 
 // CreateMockSchemaRegistryClient initializes a MockSchemaRegistryClient
 func CreateMockSchemaRegistryClient(mockURL string) *MockSchemaRegistryClient {
 	mockClient := &MockSchemaRegistryClient{
-		schemaRegistryURL: mockURL,
-		schemaVersions:    map[string]map[int]*Schema{},
-		schemaIDs:         map[int]*Schema{},
+		schemaRegistryURL:      mockURL,
+		schemaVersions:         map[string]map[int]*Schema{},
+		schemaIDs:              map[int]*Schema{},
+		deletedVersions:        map[string]map[int]*Schema{},
+		globalCompatibility:    Backward,
+		subjectCompatibilities: map[string]CompatibilityLevel{},
 	}
 
 	return mockClient
@@ -52,16 +84,157 @@ func CreateMockSchemaRegistryClient(mockURL string) *MockSchemaRegistryClient {
 // avroRegex is used to remove whitespace from the schema string
 var avroRegex = regexp.MustCompile(`\r?\n`)
 
-// CreateSchema generates a new schema with the given details, references are unused
-func (mck *MockSchemaRegistryClient) CreateSchema(subject string, schema string, schemaType SchemaType, _ ...Reference) (*Schema, error) {
+// CreateSchema generates a new schema with the given details, references are stored as given
+func (mck *MockSchemaRegistryClient) CreateSchema(subject string, schema string, schemaType SchemaType, references ...Reference) (*Schema, error) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	mck.idCounter++
+	return mck.setSchema(mck.idCounter, subject, schema, schemaType, -1, references)
+}
+
+// CreateSchemaWithID generates a new schema with the given id and version, references are stored as given.
+// A zero id or version is generated the same way CreateSchema does.
+func (mck *MockSchemaRegistryClient) CreateSchemaWithID(subject string, schema string, schemaType SchemaType, id int, version int, references ...Reference) (*Schema, error) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	if id == 0 {
+		id = mck.idCounter + 1
+	}
+	if version == 0 {
+		version = -1
+	}
+	return mck.setSchema(id, subject, schema, schemaType, version, references)
+}
+
+// ImportSchema works like CreateSchemaWithID, but returns the schema already registered under
+// the subject instead of an error when it has the same id and version. Modes aren't checked.
+func (mck *MockSchemaRegistryClient) ImportSchema(subject string, schema string, schemaType SchemaType, id int, version int, references ...Reference) (*Schema, error) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	normalized := schema
+	if schemaType == Avro || schemaType == Json {
+		normalized = avroRegex.ReplaceAllString(schema, " ")
+	}
+	for _, existing := range mck.schemaVersions[subject] {
+		if existing.schema == normalized && isImported(existing, id, version) {
+			return existing, nil
+		}
+	}
+
+	if id == 0 {
+		id = mck.idCounter + 1
+	}
+	if version == 0 {
+		version = -1
+	}
+	return mck.setSchema(id, subject, schema, schemaType, version, references)
+}
+
+// RegisterSchemaWithUpdatedReferences works like CreateSchema, but first checks the references point to existing versions
+func (mck *MockSchemaRegistryClient) RegisterSchemaWithUpdatedReferences(subject string, schema string, schemaType SchemaType, references []Reference) (*Schema, error) {
+	return registerWithCheckedReferences(mck, subject, schema, schemaType, references)
+}
+
+// CreateSchemaNormalized works like CreateSchema, but Avro and Json schemas are normalized by
+// removing their whitespace and sorting their keys, so they are found to be already registered
+// when they only differ in their formatting from another normalized schema.
+func (mck *MockSchemaRegistryClient) CreateSchemaNormalized(subject string, schema string, schemaType SchemaType, references ...Reference) (*Schema, error) {
+	if schemaType == Avro || schemaType == Json {
+		schema = normalizeSchema(schema)
+	}
+	return mck.CreateSchema(subject, schema, schemaType, references...)
+}
+
+// CreateSchemaFromFile works like CreateSchema with the schema read from the file, inferring its type from the extension
+func (mck *MockSchemaRegistryClient) CreateSchemaFromFile(subject string, path string, references ...Reference) (*Schema, error) {
+	return createSchemaFromFile(mck, subject, path, "", references)
+}
+
+// CreateSchemaFromFileWithType works like CreateSchema with the schema read from the file
+func (mck *MockSchemaRegistryClient) CreateSchemaFromFileWithType(subject string, path string, schemaType SchemaType, references ...Reference) (*Schema, error) {
+	return createSchemaFromFile(mck, subject, path, schemaType, references)
+}
+
+// CreateSchemaWithCompatibility sets the compatibility level of the subject, then works like CreateSchema
+func (mck *MockSchemaRegistryClient) CreateSchemaWithCompatibility(subject string, schema string, schemaType SchemaType, compatibility CompatibilityLevel, references ...Reference) (*Schema, error) {
+	if _, err := mck.ChangeSubjectCompatibilityLevel(subject, compatibility); err != nil {
+		return nil, err
+	}
+	return mck.CreateSchema(subject, schema, schemaType, references...)
+}
+
+// CreateSchemaAndListVersions works like CreateSchema, and returns the sorted versions of the subject as of its registration
+func (mck *MockSchemaRegistryClient) CreateSchemaAndListVersions(subject string, schema string, schemaType SchemaType, references ...Reference) (*Schema, []int, error) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	mck.idCounter++
+	created, err := mck.setSchema(mck.idCounter, subject, schema, schemaType, -1, references)
+	if err != nil {
+		return nil, nil, err
+	}
+	return created, mck.allVersions(subject), nil
+}
+
+// CreateSchemaIdempotent works like CreateSchema, but returns the schema already registered under the subject instead of an error
+func (mck *MockSchemaRegistryClient) CreateSchemaIdempotent(subject string, schema string, schemaType SchemaType, references ...Reference) (*Schema, bool, error) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	normalized := schema
+	if schemaType == Avro || schemaType == Json {
+		normalized = avroRegex.ReplaceAllString(schema, " ")
+	}
+	for _, existing := range mck.schemaVersions[subject] {
+		if existing.schema == normalized {
+			return existing, false, nil
+		}
+	}
+
+	mck.idCounter++
+	created, err := mck.setSchema(mck.idCounter, subject, schema, schemaType, -1, references)
+	if err != nil {
+		return nil, false, err
+	}
+	return created, true, nil
+}
+
+// CreateSchemaWithConfig works like CreateSchema, and stores the metadata and rule set of the request with the schema
+func (mck *MockSchemaRegistryClient) CreateSchemaWithConfig(subject string, req RegisterSchemaRequest) (*Schema, error) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
 	mck.idCounter++
-	return mck.SetSchema(mck.idCounter, subject, schema, schemaType, -1)
+	schema, err := mck.setSchema(mck.idCounter, subject, req.Schema, req.SchemaType, -1, req.References)
+	if err != nil {
+		return nil, err
+	}
+	schema.metadata = req.Metadata
+	schema.ruleSet = req.RuleSet
+	return schema, nil
+}
+
+// normalizeSchema returns the compact form of the JSON schema, with its keys sorted
+func normalizeSchema(schema string) string {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(schema), &parsed); err != nil {
+		return schema
+	}
+	normalized, err := json.Marshal(parsed)
+	if err != nil {
+		return schema
+	}
+	return string(normalized)
 }
 
 // SetSchema overwrites a schema with the given id. Allows you to set a schema with a specific ID for testing purposes.
 // Sets the ID counter to the given id if it is greater than the current counter. Version
 // is used to set the version of the schema. If version is -1, the version will be set to the next available version.
 func (mck *MockSchemaRegistryClient) SetSchema(id int, subject string, schema string, schemaType SchemaType, version int) (*Schema, error) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	return mck.setSchema(id, subject, schema, schemaType, version, nil)
+}
+
+// setSchema is SetSchema with the references the schema should be registered with
+func (mck *MockSchemaRegistryClient) setSchema(id int, subject string, schema string, schemaType SchemaType, version int, references []Reference) (*Schema, error) {
 	if id > mck.idCounter {
 		mck.idCounter = id
 	}
@@ -77,7 +250,7 @@ func (mck *MockSchemaRegistryClient) SetSchema(id int, subject string, schema st
 
 	resultFromSchemaCache, ok := mck.schemaVersions[subject]
 	if !ok {
-		return mck.generateVersion(id, subject, schema, schemaType, version)
+		return mck.generateVersion(id, subject, schema, schemaType, version, references)
 	}
 
 	// Verify if it's not the same schema as an existing version
@@ -92,11 +265,13 @@ func (mck *MockSchemaRegistryClient) SetSchema(id int, subject string, schema st
 		}
 	}
 
-	return mck.generateVersion(id, subject, schema, schemaType, version)
+	return mck.generateVersion(id, subject, schema, schemaType, version, references)
 }
 
 // GetSchema Returns a Schema for the given ID
 func (mck *MockSchemaRegistryClient) GetSchema(schemaID int) (*Schema, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
 	thisSchema, ok := mck.schemaIDs[schemaID]
 	if !ok {
 		posErr := url.Error{
@@ -110,6 +285,114 @@ func (mck *MockSchemaRegistryClient) GetSchema(schemaID int) (*Schema, error) {
 	return thisSchema, nil
 }
 
+// GetSchemaWithContext Returns the Schema for the given ID like GetSchema, unless the context is done
+func (mck *MockSchemaRegistryClient) GetSchemaWithContext(ctx context.Context, schemaID int) (*Schema, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return mck.GetSchema(schemaID)
+}
+
+// GetSchemasByIDs Returns the Schemas for the given IDs, along with a *SchemasByIDsError for the missing ones
+func (mck *MockSchemaRegistryClient) GetSchemasByIDs(schemaIDs []int) (map[int]*Schema, error) {
+	schemas := make(map[int]*Schema, len(schemaIDs))
+	errs := make(map[int]error)
+	for _, schemaID := range schemaIDs {
+		thisSchema, err := mck.GetSchema(schemaID)
+		if err != nil {
+			errs[schemaID] = err
+			continue
+		}
+		schemas[schemaID] = thisSchema
+	}
+
+	if len(errs) > 0 {
+		return schemas, &SchemasByIDsError{Errors: errs}
+	}
+	return schemas, nil
+}
+
+// GetAllVersionsForSubject Returns all versions of the given subject, sorted by version
+func (mck *MockSchemaRegistryClient) GetAllVersionsForSubject(subject string) ([]*Schema, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	versions := mck.allVersions(subject)
+	schemas := make([]*Schema, 0, len(versions))
+	for _, version := range versions {
+		schemas = append(schemas, mck.schemaVersions[subject][version])
+	}
+	return schemas, nil
+}
+
+// ReimportSubject registers every version of the subject into the target client, in the order of their versions
+func (mck *MockSchemaRegistryClient) ReimportSubject(targetClient ISchemaRegistryClient, subject string) error {
+	return reimportSubject(mck, targetClient, subject)
+}
+
+// GetSchemaExpanded Returns the schema for the given ID with the types of its references inlined
+func (mck *MockSchemaRegistryClient) GetSchemaExpanded(schemaID int) (string, error) {
+	thisSchema, err := mck.GetSchema(schemaID)
+	if err != nil {
+		return "", err
+	}
+	return expandSchema(mck, thisSchema)
+}
+
+// GetSchemaFormat Returns the schema for the given ID, with the types of its references inlined for the "resolved" format.
+// Other formats are not implemented.
+func (mck *MockSchemaRegistryClient) GetSchemaFormat(schemaID int, format string) (*Schema, error) {
+	thisSchema, err := mck.GetSchema(schemaID)
+	if err != nil || format == "" {
+		return thisSchema, err
+	}
+	if format != "resolved" {
+		return nil, errNotImplemented
+	}
+
+	resolved, err := expandSchema(mck, thisSchema)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{
+		id:         thisSchema.id,
+		schema:     resolved,
+		schemaType: thisSchema.schemaType,
+		version:    thisSchema.version,
+		references: thisSchema.references,
+		metadata:   thisSchema.metadata,
+		ruleSet:    thisSchema.ruleSet,
+	}, nil
+}
+
+// DiffSchemaVersions Returns the fields added, removed and modified between two versions of the subject
+func (mck *MockSchemaRegistryClient) DiffSchemaVersions(subject string, fromVersion, toVersion int) (SchemaDiff, error) {
+	return diffSchemaVersions(mck, subject, fromVersion, toVersion)
+}
+
+// GetRawSchema Returns the schema string for the given ID
+func (mck *MockSchemaRegistryClient) GetRawSchema(schemaID int) (string, error) {
+	thisSchema, err := mck.GetSchema(schemaID)
+	if err != nil {
+		return "", err
+	}
+	return thisSchema.Schema(), nil
+}
+
+// GetSchemaWithSubjects Returns the schema string for the given ID along with its subject-version pairs
+func (mck *MockSchemaRegistryClient) GetSchemaWithSubjects(schemaID int) (string, SubjectVersionResponse, error) {
+	schema, err := mck.GetRawSchema(schemaID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	subjectVersions, err := mck.GetSubjectVersionsById(schemaID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return schema, subjectVersions, nil
+}
+
 // GetLatestSchema Returns the highest ordinal version of a Schema for a given `concrete subject`
 func (mck *MockSchemaRegistryClient) GetLatestSchema(subject string) (*Schema, error) {
 	// Error is never returned
@@ -126,14 +409,55 @@ func (mck *MockSchemaRegistryClient) GetLatestSchema(subject string) (*Schema, e
 	return thisSchema, nil
 }
 
+// GetLatestSchemaWithContext returns the latest version of the subject like GetLatestSchema, unless the context is done
+func (mck *MockSchemaRegistryClient) GetLatestSchemaWithContext(ctx context.Context, subject string) (*Schema, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return mck.GetLatestSchema(subject)
+}
+
+// GetLatestSchemaMetadata Returns the metadata of the highest ordinal version of a Schema for a given `concrete subject`
+func (mck *MockSchemaRegistryClient) GetLatestSchemaMetadata(subject string) (*SchemaMetadata, error) {
+	thisSchema, err := mck.GetLatestSchema(subject)
+	if err != nil {
+		return nil, err
+	}
+	return newSchemaMetadata(subject, thisSchema), nil
+}
+
+// GetLatestSchemaWithReferences Returns the highest ordinal version of a Schema for a given subject, along with the schemas it references
+func (mck *MockSchemaRegistryClient) GetLatestSchemaWithReferences(subject string) (*Schema, map[string]*Schema, error) {
+	thisSchema, err := mck.GetLatestSchema(subject)
+	if err != nil {
+		return nil, nil, err
+	}
+	references, err := resolveReferences(mck, thisSchema.references)
+	if err != nil {
+		return nil, nil, err
+	}
+	return thisSchema, references, nil
+}
+
 // GetSchemaVersions Returns the array of versions this subject has previously registered
 func (mck *MockSchemaRegistryClient) GetSchemaVersions(subject string) ([]int, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
 	versions := mck.allVersions(subject)
 	return versions, nil
 }
 
+// SubjectExists Returns whether the subject has any version registered
+func (mck *MockSchemaRegistryClient) SubjectExists(subject string) (bool, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	return len(mck.schemaVersions[subject]) > 0, nil
+}
+
 // GetSubjectVersionsById Returns subject-version pairs identified by the schema ID.
 func (mck *MockSchemaRegistryClient) GetSubjectVersionsById(schemaID int) (SubjectVersionResponse, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
 	for subjectName, schemaVersionsMap := range mck.schemaVersions {
 		for _, schema := range schemaVersionsMap {
 			if schema.id == schemaID {
@@ -161,8 +485,36 @@ func (mck *MockSchemaRegistryClient) GetSubjectVersionsById(schemaID int) (Subje
 	return nil, &posErr
 }
 
+// GetSchemaByVersionString Returns the given Schema according to the passed in subject and version, "latest" or a number
+func (mck *MockSchemaRegistryClient) GetSchemaByVersionString(subject string, version string) (*Schema, error) {
+	version, err := parseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	if version == "latest" {
+		return mck.GetLatestSchema(subject)
+	}
+	number, _ := strconv.Atoi(version)
+	return mck.GetSchemaByVersion(subject, number)
+}
+
 // GetSchemaByVersion Returns the given Schema according to the passed in subject and version number
 func (mck *MockSchemaRegistryClient) GetSchemaByVersion(subject string, version int) (*Schema, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	return mck.schemaByVersion(subject, version)
+}
+
+// GetSchemaByVersionWithContext Returns the given Schema like GetSchemaByVersion, unless the context is done
+func (mck *MockSchemaRegistryClient) GetSchemaByVersionWithContext(ctx context.Context, subject string, version int) (*Schema, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return mck.GetSchemaByVersion(subject, version)
+}
+
+// schemaByVersion is GetSchemaByVersion for callers already holding the lock
+func (mck *MockSchemaRegistryClient) schemaByVersion(subject string, version int) (*Schema, error) {
 	var schema *Schema
 	schemaVersionMap, ok := mck.schemaVersions[subject]
 	if !ok {
@@ -191,8 +543,33 @@ func (mck *MockSchemaRegistryClient) GetSchemaByVersion(subject string, version
 	return schema, nil
 }
 
+// GetSchemaByVersionIncludingDeleted works like GetSchemaByVersion, but also returns soft deleted versions
+func (mck *MockSchemaRegistryClient) GetSchemaByVersionIncludingDeleted(subject string, version int) (*Schema, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	if schema, ok := mck.deletedVersions[subject][version]; ok {
+		return schema, nil
+	}
+	return mck.schemaByVersion(subject, version)
+}
+
 // GetSubjects Returns all registered subjects
 func (mck *MockSchemaRegistryClient) GetSubjects() ([]string, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	return mck.subjects(), nil
+}
+
+// GetSubjectsWithContext returns all registered subjects, unless the context is done
+func (mck *MockSchemaRegistryClient) GetSubjectsWithContext(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return mck.GetSubjects()
+}
+
+// subjects returns all registered subjects, for callers already holding the lock
+func (mck *MockSchemaRegistryClient) subjects() []string {
 	allSubjects := make([]string, len(mck.schemaVersions))
 
 	var count int
@@ -201,7 +578,116 @@ func (mck *MockSchemaRegistryClient) GetSubjects() ([]string, error) {
 		count++
 	}
 
-	return allSubjects, nil
+	return allSubjects
+}
+
+// StreamSubjects calls fn with every subject, ordered by name, until it returns an error
+func (mck *MockSchemaRegistryClient) StreamSubjects(fn func(subject string) error) error {
+	allSubjects, _ := mck.GetSubjects()
+	sort.Strings(allSubjects)
+	for _, subject := range allSubjects {
+		if err := fn(subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSubjectsByPrefix Returns the subjects starting with the given prefix. Subjects whose versions are all soft
+// deleted are only returned if includeDeleted is true, while subjects deleted with DeleteSubject are always left out
+func (mck *MockSchemaRegistryClient) GetSubjectsByPrefix(prefix string, includeDeleted bool) ([]string, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	subjects := make([]string, 0)
+	for subject, versions := range mck.schemaVersions {
+		if !strings.HasPrefix(subject, prefix) {
+			continue
+		}
+		if len(versions) > 0 || (includeDeleted && len(mck.deletedVersions[subject]) > 0) {
+			subjects = append(subjects, subject)
+		}
+	}
+
+	return subjects, nil
+}
+
+// GetAllSchemas Returns the schemas of all subjects, ordered by subject and version
+func (mck *MockSchemaRegistryClient) GetAllSchemas(latestOnly bool, offset, limit int) ([]SchemaMetadata, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	allSubjects := mck.subjects()
+	sort.Strings(allSubjects)
+
+	allSchemas := make([]SchemaMetadata, 0)
+	for _, subject := range allSubjects {
+		versions := mck.allVersions(subject)
+		if latestOnly && len(versions) > 0 {
+			versions = versions[len(versions)-1:]
+		}
+		for _, version := range versions {
+			schema := mck.schemaVersions[subject][version]
+			schemaType := Avro
+			if schema.schemaType != nil {
+				schemaType = *schema.schemaType
+			}
+			allSchemas = append(allSchemas, SchemaMetadata{
+				Subject:    subject,
+				Version:    schema.version,
+				ID:         schema.id,
+				SchemaType: schemaType,
+				Schema:     schema.schema,
+				References: schema.references,
+			})
+		}
+	}
+
+	if offset > len(allSchemas) {
+		offset = len(allSchemas)
+	}
+	allSchemas = allSchemas[offset:]
+	if limit > 0 && limit < len(allSchemas) {
+		allSchemas = allSchemas[:limit]
+	}
+
+	return allSchemas, nil
+}
+
+// GetAllSchemasWithContext returns the schemas of every subject like GetAllSchemas, unless the context is done
+func (mck *MockSchemaRegistryClient) GetAllSchemasWithContext(ctx context.Context, latestOnly bool, offset, limit int) ([]SchemaMetadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return mck.GetAllSchemas(latestOnly, offset, limit)
+}
+
+// ResolveVersionByContent Returns the earliest version of the subject registered with the schema, ignoring whitespace
+func (mck *MockSchemaRegistryClient) ResolveVersionByContent(subject string, schema string, schemaType SchemaType) (int, error) {
+	return resolveVersionByContent(mck, subject, schema, schemaType)
+}
+
+// FindSubjectsWithSchema Returns the subjects and versions the schema is registered with, ignoring whitespace
+func (mck *MockSchemaRegistryClient) FindSubjectsWithSchema(schema string, schemaType SchemaType) (SubjectVersionResponse, error) {
+	return findSubjectsWithSchema(mck, schema, schemaType)
+}
+
+// GetMaxSchemaID Returns the highest ID among the registered schemas, including the soft deleted ones
+func (mck *MockSchemaRegistryClient) GetMaxSchemaID() (int, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	var maxID int
+	for id := range mck.schemaIDs {
+		if id > maxID {
+			maxID = id
+		}
+	}
+	for _, versions := range mck.deletedVersions {
+		for _, schema := range versions {
+			if schema.id > maxID {
+				maxID = schema.id
+			}
+		}
+	}
+	return maxID, nil
 }
 
 // GetSchemaRegistryURL returns the URL of the schema registry
@@ -209,6 +695,28 @@ func (mck *MockSchemaRegistryClient) GetSchemaRegistryURL() string {
 	return mck.schemaRegistryURL
 }
 
+// Ping returns the error set with SetPingError, which is nil by default
+func (mck *MockSchemaRegistryClient) Ping() error {
+	return mck.PingWithContext(context.Background())
+}
+
+// PingWithContext returns the error set with SetPingError, unless the context is done
+func (mck *MockSchemaRegistryClient) PingWithContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	return mck.pingErr
+}
+
+// SetPingError sets the error returned by Ping, to test how an unreachable registry is handled
+func (mck *MockSchemaRegistryClient) SetPingError(err error) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	mck.pingErr = err
+}
+
 // GetSubjectsIncludingDeleted is not implemented and returns an error
 func (mck *MockSchemaRegistryClient) GetSubjectsIncludingDeleted() ([]string, error) {
 	return nil, errNotImplemented
@@ -216,12 +724,65 @@ func (mck *MockSchemaRegistryClient) GetSubjectsIncludingDeleted() ([]string, er
 
 // DeleteSubject removes given subject from the cache
 func (mck *MockSchemaRegistryClient) DeleteSubject(subject string, _ bool) error {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
 	delete(mck.schemaVersions, subject)
 	return nil
 }
 
-// DeleteSubjectByVersion removes given subject's version from cache
-func (mck *MockSchemaRegistryClient) DeleteSubjectByVersion(subject string, version int, _ bool) error {
+// DeleteAllSubjects removes all subjects from the cache, unless dryRun is true, and returns them sorted by name
+func (mck *MockSchemaRegistryClient) DeleteAllSubjects(_ bool, dryRun bool) ([]string, error) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	allSubjects := mck.subjects()
+	sort.Strings(allSubjects)
+	if !dryRun {
+		mck.schemaVersions = map[string]map[int]*Schema{}
+	}
+	return allSubjects, nil
+}
+
+// DeleteSubjectByVersion removes given subject's version from cache. Unless permanent is true,
+// the version is kept as soft deleted, so GetSchemaByVersionIncludingDeleted still returns it.
+func (mck *MockSchemaRegistryClient) DeleteSubjectByVersion(subject string, version int, permanent bool) error {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	return mck.deleteVersion(subject, version, permanent)
+}
+
+// DeleteSubjectVersionLatest removes the highest version of the subject and returns it
+func (mck *MockSchemaRegistryClient) DeleteSubjectVersionLatest(subject string, permanent bool) (int, error) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	latest := 0
+	for version := range mck.schemaVersions[subject] {
+		if version > latest {
+			latest = version
+		}
+	}
+	if latest == 0 {
+		posErr := url.Error{
+			Op:  "DELETE",
+			URL: fmt.Sprintf("%s/subjects/%s/versions/latest", mck.schemaRegistryURL, subject),
+			Err: errSubjectNotFound,
+		}
+		return 0, &posErr
+	}
+	if err := mck.deleteVersion(subject, latest, permanent); err != nil {
+		return 0, err
+	}
+	return latest, nil
+}
+
+// deleteVersion removes the version of the subject, the caller must hold the registry lock
+func (mck *MockSchemaRegistryClient) deleteVersion(subject string, version int, permanent bool) error {
+	if permanent {
+		if _, ok := mck.deletedVersions[subject][version]; ok {
+			delete(mck.deletedVersions[subject], version)
+			return nil
+		}
+	}
+
 	_, ok := mck.schemaVersions[subject]
 	if !ok {
 		posErr := url.Error{
@@ -232,9 +793,15 @@ func (mck *MockSchemaRegistryClient) DeleteSubjectByVersion(subject string, vers
 		return &posErr
 	}
 
-	for schemaVersion := range mck.schemaVersions[subject] {
+	for schemaVersion, schema := range mck.schemaVersions[subject] {
 		if schemaVersion == version {
 			delete(mck.schemaVersions[subject], schemaVersion)
+			if !permanent {
+				if _, ok := mck.deletedVersions[subject]; !ok {
+					mck.deletedVersions[subject] = map[int]*Schema{}
+				}
+				mck.deletedVersions[subject][schemaVersion] = schema
+			}
 			return nil
 		}
 	}
@@ -247,29 +814,223 @@ func (mck *MockSchemaRegistryClient) DeleteSubjectByVersion(subject string, vers
 	return &posErr
 }
 
-// ChangeSubjectCompatibilityLevel is not implemented
-func (mck *MockSchemaRegistryClient) ChangeSubjectCompatibilityLevel(string, CompatibilityLevel) (*CompatibilityLevel, error) {
-	return nil, errNotImplemented
+// DeleteSchemaByID removes the schema with the given ID and every subject version registered with it from the cache.
+// Unless permanent is true, the versions are kept as soft deleted, like DeleteSubjectByVersion does.
+func (mck *MockSchemaRegistryClient) DeleteSchemaByID(schemaID int, permanent bool) error {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	if _, ok := mck.schemaIDs[schemaID]; !ok {
+		posErr := url.Error{
+			Op:  "GET",
+			URL: fmt.Sprintf("%s/schemas/ids/%d/versions", mck.schemaRegistryURL, schemaID),
+			Err: errSchemaNotFound,
+		}
+		return &posErr
+	}
+
+	delete(mck.schemaIDs, schemaID)
+	for subject, versions := range mck.schemaVersions {
+		for version, schema := range versions {
+			if schema.id != schemaID {
+				continue
+			}
+			if err := mck.deleteVersion(subject, version, permanent); err != nil {
+				return err
+			}
+		}
+		if len(versions) == 0 {
+			delete(mck.schemaVersions, subject)
+		}
+	}
+	return nil
+}
+
+// GetSchemaTypes Returns all the schema types, as the mock supports all of them
+func (mck *MockSchemaRegistryClient) GetSchemaTypes() ([]SchemaType, error) {
+	return []SchemaType{Json, Protobuf, Avro}, nil
+}
+
+// GetReferencedBy Returns the IDs of the schemas referencing the given subject version
+func (mck *MockSchemaRegistryClient) GetReferencedBy(subject string, version int) ([]int, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	if _, err := mck.schemaByVersion(subject, version); err != nil {
+		return nil, err
+	}
+
+	referencedBy := make([]int, 0)
+	for id, schema := range mck.schemaIDs {
+		for _, reference := range schema.references {
+			if reference.Subject == subject && reference.Version == version {
+				referencedBy = append(referencedBy, id)
+				break
+			}
+		}
+	}
+	sort.Ints(referencedBy)
+
+	return referencedBy, nil
+}
+
+// PurgeSubjectVersion removes given subject's version from cache
+func (mck *MockSchemaRegistryClient) PurgeSubjectVersion(subject string, version int) error {
+	return mck.DeleteSubjectByVersion(subject, version, true)
+}
+
+// ChangeSubjectCompatibilityLevel sets the compatibility level of the subject
+func (mck *MockSchemaRegistryClient) ChangeSubjectCompatibilityLevel(subject string, compatibility CompatibilityLevel) (*CompatibilityLevel, error) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	mck.subjectCompatibilities[subject] = compatibility
+	return &compatibility, nil
+}
+
+// DeleteSubjectCompatibilityLevel removes the compatibility level of the subject and returns the global one it reverts to
+func (mck *MockSchemaRegistryClient) DeleteSubjectCompatibilityLevel(subject string) (*CompatibilityLevel, error) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	delete(mck.subjectCompatibilities, subject)
+	compatibility := mck.globalCompatibility
+	return &compatibility, nil
 }
 
-// GetGlobalCompatibilityLevel is not implemented
+// GetGlobalCompatibilityLevel returns the global compatibility level, which defaults to BACKWARD
 func (mck *MockSchemaRegistryClient) GetGlobalCompatibilityLevel() (*CompatibilityLevel, error) {
-	return nil, errNotImplemented
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	compatibility := mck.globalCompatibility
+	return &compatibility, nil
 }
 
-// GetCompatibilityLevel is not implemented
-func (mck *MockSchemaRegistryClient) GetCompatibilityLevel(string, bool) (*CompatibilityLevel, error) {
-	return nil, errNotImplemented
+// GetGlobalConfig returns the global configuration, along with the global compatibility level
+func (mck *MockSchemaRegistryClient) GetGlobalConfig() (*RegistryConfig, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	registryConfig := mck.globalConfig
+	registryConfig.CompatibilityLevel = mck.globalCompatibility
+	return &registryConfig, nil
 }
 
-// SetCredentials is not implemented
-func (mck *MockSchemaRegistryClient) SetCredentials(string, string) {
-	// Nothing because mockSchemaRegistryClient is actually very vulnerable
+// UpdateGlobalConfig updates the global settings that are set in the given configuration and returns them
+func (mck *MockSchemaRegistryClient) UpdateGlobalConfig(registryConfig RegistryConfig) (*RegistryConfig, error) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	if registryConfig.CompatibilityLevel != "" {
+		mck.globalCompatibility = registryConfig.CompatibilityLevel
+	}
+	if registryConfig.Alias != "" {
+		mck.globalConfig.Alias = registryConfig.Alias
+	}
+	if registryConfig.Normalize != nil {
+		mck.globalConfig.Normalize = registryConfig.Normalize
+	}
+	if registryConfig.ValidateFields != nil {
+		mck.globalConfig.ValidateFields = registryConfig.ValidateFields
+	}
+	if registryConfig.CompatibilityGroup != "" {
+		mck.globalConfig.CompatibilityGroup = registryConfig.CompatibilityGroup
+	}
+	if registryConfig.DefaultMetadata != nil {
+		mck.globalConfig.DefaultMetadata = registryConfig.DefaultMetadata
+	}
+	if registryConfig.OverrideMetadata != nil {
+		mck.globalConfig.OverrideMetadata = registryConfig.OverrideMetadata
+	}
+	if registryConfig.DefaultRuleSet != nil {
+		mck.globalConfig.DefaultRuleSet = registryConfig.DefaultRuleSet
+	}
+	if registryConfig.OverrideRuleSet != nil {
+		mck.globalConfig.OverrideRuleSet = registryConfig.OverrideRuleSet
+	}
+	return &registryConfig, nil
 }
 
-// SetBearerToken is not implemented
-func (mck *MockSchemaRegistryClient) SetBearerToken(string) {
-	// Nothing because mockSchemaRegistryClient is actually very vulnerable
+// GetSubjectConfig returns the configuration of the subject, which only holds its own compatibility level
+func (mck *MockSchemaRegistryClient) GetSubjectConfig(subject string) (*RegistryConfig, error) {
+	compatibility, err := mck.GetCompatibilityLevel(subject, false)
+	if err != nil {
+		return nil, err
+	}
+	return &RegistryConfig{CompatibilityLevel: *compatibility}, nil
+}
+
+// GetCompatibilityLevel returns the compatibility level of the subject, falling back to the global one if defaultToGlobal is set
+func (mck *MockSchemaRegistryClient) GetCompatibilityLevel(subject string, defaultToGlobal bool) (*CompatibilityLevel, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	compatibility, ok := mck.subjectCompatibilities[subject]
+	if ok {
+		return &compatibility, nil
+	}
+	if defaultToGlobal {
+		compatibility = mck.globalCompatibility
+		return &compatibility, nil
+	}
+
+	posErr := url.Error{
+		Op:  "GET",
+		URL: fmt.Sprintf("%s/config/%s", mck.schemaRegistryURL, subject),
+		Err: errCompatibilityNotFound,
+	}
+	return nil, &posErr
+}
+
+// GetCompatibilityLevels Returns the compatibility level of every subject, along with the failures
+func (mck *MockSchemaRegistryClient) GetCompatibilityLevels(subjects []string, defaultToGlobal bool) (map[string]CompatibilityLevel, error) {
+	levels := make(map[string]CompatibilityLevel, len(subjects))
+	errs := make(map[string]error)
+	for _, subject := range subjects {
+		level, err := mck.GetCompatibilityLevel(subject, defaultToGlobal)
+		if err != nil {
+			errs[subject] = err
+			continue
+		}
+		levels[subject] = *level
+	}
+
+	if len(errs) > 0 {
+		return levels, &CompatibilityLevelsError{Errors: errs}
+	}
+	return levels, nil
+}
+
+// GetEffectiveCompatibility returns the compatibility level of the subject, and whether it's inherited from the global one
+func (mck *MockSchemaRegistryClient) GetEffectiveCompatibility(subject string) (CompatibilityLevel, bool, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	if compatibility, ok := mck.subjectCompatibilities[subject]; ok {
+		return compatibility, false, nil
+	}
+	return mck.globalCompatibility, true, nil
+}
+
+// SetCredentials records the given credentials, which can be read back with Credentials
+func (mck *MockSchemaRegistryClient) SetCredentials(username string, password string) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	mck.username = username
+	mck.password = password
+}
+
+// Credentials Returns the username and password last given to SetCredentials
+func (mck *MockSchemaRegistryClient) Credentials() (string, string) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	return mck.username, mck.password
+}
+
+// SetBearerToken records the given token, which can be read back with BearerToken
+func (mck *MockSchemaRegistryClient) SetBearerToken(token string) {
+	mck.registryLock.Lock()
+	defer mck.registryLock.Unlock()
+	mck.bearerToken = token
+}
+
+// BearerToken Returns the token last given to SetBearerToken
+func (mck *MockSchemaRegistryClient) BearerToken() string {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	return mck.bearerToken
 }
 
 // SetTimeout is not implemented
@@ -287,6 +1048,27 @@ func (mck *MockSchemaRegistryClient) ResetCache() {
 	// Nothing because there is no lock for cache
 }
 
+// EvictSubject is not implemented
+func (mck *MockSchemaRegistryClient) EvictSubject(string) {
+	// Nothing because there is no cache to evict from
+}
+
+// EvictSchemaID is not implemented
+func (mck *MockSchemaRegistryClient) EvictSchemaID(int) {
+	// Nothing because there is no cache to evict from
+}
+
+// CacheStats is not implemented
+func (mck *MockSchemaRegistryClient) CacheStats() CacheStats {
+	// Nothing because there is no cache to measure
+	return CacheStats{}
+}
+
+// ResetCacheStats is not implemented
+func (mck *MockSchemaRegistryClient) ResetCacheStats() {
+	// Nothing because there is no cache to measure
+}
+
 // CodecCreationEnabled is not implemented
 func (mck *MockSchemaRegistryClient) CodecCreationEnabled(bool) {
 	// Nothing because codecs do not matter in the inMem storage of schemas
@@ -302,11 +1084,150 @@ func (mck *MockSchemaRegistryClient) IsSchemaCompatible(string, string, string,
 	return false, errNotImplemented
 }
 
+// IsSchemaCompatibleWithAll checks the schema against the versions of the subject, according to its compatibility level:
+// transitive levels check all versions, while the others only check the latest one, like Schema Registry does. Only Avro record fields are evaluated: fields added by the reader's schema need a default, and other Avro schemas must
+// be equal. Json and Protobuf schemas are always considered compatible.
+func (mck *MockSchemaRegistryClient) IsSchemaCompatibleWithAll(subject, schema string, schemaType SchemaType, _ ...Reference) (bool, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	versions := mck.allVersions(subject)
+	if len(versions) == 0 {
+		posErr := url.Error{
+			Op:  "POST",
+			URL: fmt.Sprintf("%s/compatibility/subjects/%s/versions", mck.schemaRegistryURL, subject),
+			Err: errSubjectNotFound,
+		}
+		return false, &posErr
+	}
+	if schemaType != Avro {
+		return true, nil
+	}
+
+	compatibility, ok := mck.subjectCompatibilities[subject]
+	if !ok {
+		compatibility = mck.globalCompatibility
+	}
+	if !strings.HasSuffix(string(compatibility), "_TRANSITIVE") {
+		versions = versions[len(versions)-1:]
+	}
+	for _, version := range versions {
+		existing := mck.schemaVersions[subject][version].schema
+		switch compatibility {
+		case Backward, BackwardTransitive:
+			if !avroCanRead(schema, existing) {
+				return false, nil
+			}
+		case Forward, ForwardTransitive:
+			if !avroCanRead(existing, schema) {
+				return false, nil
+			}
+		case Full, FullTransitive:
+			if !avroCanRead(schema, existing) || !avroCanRead(existing, schema) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// avroCanRead tells whether data written with the writer's schema can be read with the reader's
+// schema, only looking at record fields: the fields missing from the writer's need a default
+func avroCanRead(reader, writer string) bool {
+	readerSchema, readerIsRecord := avroRecord(reader)
+	writerSchema, writerIsRecord := avroRecord(writer)
+	if !readerIsRecord || !writerIsRecord {
+		return normalizeSchema(reader) == normalizeSchema(writer)
+	}
+
+	writerFields := make(map[string]bool)
+	for _, field := range avroFields(writerSchema) {
+		writerFields[fmt.Sprint(field["name"])] = true
+	}
+	for _, field := range avroFields(readerSchema) {
+		if _, hasDefault := field["default"]; !writerFields[fmt.Sprint(field["name"])] && !hasDefault {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckSchemaCompatibility is not implemented
+func (mck *MockSchemaRegistryClient) CheckSchemaCompatibility(string, string, string, SchemaType, ...Reference) (CompatibilityResult, error) {
+	return CompatibilityResult{}, errNotImplemented
+}
+
+// CheckSchemasCompatible is not implemented
+func (mck *MockSchemaRegistryClient) CheckSchemasCompatible(string, []SchemaCandidate) ([]CompatibilityResult, error) {
+	return nil, errNotImplemented
+}
+
 // LookupSchema is not implemented
 func (mck *MockSchemaRegistryClient) LookupSchema(string, string, SchemaType, ...Reference) (*Schema, error) {
 	return nil, errNotImplemented
 }
 
+// LookupSchemaNormalized Returns the schema registered under the subject like FindSchemaVersion, but Avro and Json
+// schemas are normalized before being compared, so they are found when they only differ in their formatting.
+// Both a missing subject and a missing schema return an error matching errSchemaNotFound.
+func (mck *MockSchemaRegistryClient) LookupSchemaNormalized(subject string, schema string, schemaType SchemaType, _ ...Reference) (*Schema, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	normalize := func(schema string) string { return schema }
+	if schemaType == Avro || schemaType == Json {
+		normalize = normalizeSchema
+	}
+	normalized := normalize(schema)
+	for _, existing := range mck.schemaVersions[subject] {
+		if normalize(existing.schema) == normalized {
+			return existing, nil
+		}
+	}
+
+	posErr := url.Error{
+		Op:  "POST",
+		URL: fmt.Sprintf("%s/subjects/%s?normalize=true", mck.schemaRegistryURL, subject),
+		Err: errSchemaNotFound,
+	}
+	return nil, &posErr
+}
+
+// FindSchemaVersion Returns the version and ID of the schema registered under the subject.
+// Both a missing subject and a missing schema return an error matching errSchemaNotFound.
+func (mck *MockSchemaRegistryClient) FindSchemaVersion(subject string, schema string, schemaType SchemaType, _ ...Reference) (int, int, error) {
+	mck.registryLock.RLock()
+	defer mck.registryLock.RUnlock()
+	if schemaType == Avro || schemaType == Json {
+		schema = avroRegex.ReplaceAllString(schema, " ")
+	}
+	for version, existing := range mck.schemaVersions[subject] {
+		if existing.schema == schema {
+			return version, existing.id, nil
+		}
+	}
+
+	posErr := url.Error{
+		Op:  "POST",
+		URL: fmt.Sprintf("%s/subjects/%s", mck.schemaRegistryURL, subject),
+		Err: errSchemaNotFound,
+	}
+	return 0, 0, &posErr
+}
+
+// GetGlobalMode is not implemented
+func (mck *MockSchemaRegistryClient) GetGlobalMode() (Mode, error) {
+	return "", errNotImplemented
+}
+
+// GetMode is not implemented
+func (mck *MockSchemaRegistryClient) GetMode(string) (Mode, error) {
+	return "", errNotImplemented
+}
+
+// UpdateMode is not implemented
+func (mck *MockSchemaRegistryClient) UpdateMode(string, Mode, bool) (Mode, error) {
+	return "", errNotImplemented
+}
+
 /*
 These classes are written as helpers and therefore, are not exported.
 generateVersion will register a new version of the schema passed, it will NOT do any checks
@@ -317,7 +1238,7 @@ qualify for key/value subjects, it expects to have a `concrete subject` passed o
 */
 
 // generateVersion the next version of the schema for the given subject, givenVersion can be set to -1 to generate one.
-func (mck *MockSchemaRegistryClient) generateVersion(id int, subject string, schema string, schemaType SchemaType, givenVersion int) (*Schema, error) {
+func (mck *MockSchemaRegistryClient) generateVersion(id int, subject string, schema string, schemaType SchemaType, givenVersion int, references []Reference) (*Schema, error) {
 	schemaVersionMap := map[int]*Schema{}
 	currentVersion := 1
 
@@ -348,6 +1269,7 @@ func (mck *MockSchemaRegistryClient) generateVersion(id int, subject string, sch
 		version:    currentVersion,
 		codec:      codec,
 		schemaType: &schemaType,
+		references: references,
 	}
 
 	schemaVersionMap[currentVersion] = schemaToRegister
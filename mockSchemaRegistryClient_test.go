@@ -1,8 +1,18 @@
 package srclient
 
 import (
-	"github.com/stretchr/testify/assert"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
 	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -217,6 +227,44 @@ func TestMockSchemaRegistryClient_SetSchema_RegistersSchemaCorrectly(t *testing.
 	}
 }
 
+func TestMockSchemaRegistryClient_CreateSchemaWithID_RegistersSchemaCorrectly(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	// Act
+	withID, err := registry.CreateSchemaWithID("cupcake", testSchema1, Avro, 42, 7)
+	assert.NoError(t, err)
+	generated, err := registry.CreateSchemaWithID("cupcake", testSchema2, Avro, 0, 0)
+	assert.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 42, withID.id)
+	assert.Equal(t, 7, withID.version)
+	assert.Equal(t, 43, generated.id)
+	assert.Equal(t, 8, generated.version)
+	assert.Equal(t, withID, registry.schemaIDs[42])
+	assert.Equal(t, generated, registry.schemaVersions["cupcake"][8])
+}
+
+func TestMockSchemaRegistryClient_ImportSchema_IsIdempotent(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	imported, err := registry.ImportSchema("cupcake", testSchema1, Avro, 42, 7)
+	assert.NoError(t, err)
+
+	// Act
+	again, err := registry.ImportSchema("cupcake", testSchema1, Avro, 42, 7)
+	_, conflictErr := registry.ImportSchema("cupcake", testSchema1, Avro, 43, 8)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Same(t, imported, again)
+	assert.ErrorIs(t, conflictErr, errSchemaAlreadyRegistered)
+	assert.Len(t, registry.schemaVersions["cupcake"], 1)
+}
+
 func TestMockSchemaRegistryClient_SetSchema_CorrectlyUpdatesIdCounter(t *testing.T) {
 	t.Parallel()
 	tests := map[string]struct {
@@ -258,6 +306,42 @@ func TestMockSchemaRegistryClient_SetSchema_CorrectlyUpdatesIdCounter(t *testing
 	}
 }
 
+func TestMockSchemaRegistryClient_CreateSchema_IsSafeForConcurrentUse(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	const workers = 20
+	ids := make(chan int, workers)
+
+	// Act
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			subject := fmt.Sprintf("cupcake-%d", i)
+			schema := fmt.Sprintf(`{"type": "record", "name": "cupcake%d", "fields": []}`, i)
+			created, err := registry.CreateSchema(subject, schema, Avro)
+			if assert.NoError(t, err) {
+				ids <- created.ID()
+			}
+			_, _ = registry.GetLatestSchema(subject)
+			_, _ = registry.GetSubjects()
+		}(i)
+	}
+	wg.Wait()
+	close(ids)
+
+	// Assert
+	seen := make(map[int]bool)
+	for id := range ids {
+		assert.False(t, seen[id], "schema ID %d was assigned twice", id)
+		seen[id] = true
+	}
+	assert.Len(t, seen, workers)
+	assert.Equal(t, workers, registry.idCounter)
+}
+
 func TestMockSchemaRegistryClient_CreateSchema_ReturnsErrorOnInvalidSchemaType(t *testing.T) {
 	t.Parallel()
 	// Arrange
@@ -290,112 +374,93 @@ func TestMockSchemaRegistryClient_CreateSchema_ReturnsErrorOnDuplicateSchema(t *
 	assert.ErrorIs(t, err, errSchemaAlreadyRegistered)
 }
 
-func TestMockSchemaRegistryClient_GetSchema_ReturnsSchema(t *testing.T) {
+func TestMockSchemaRegistryClient_CreateSchemaNormalized_ReturnsErrorOnReformattedSchema(t *testing.T) {
 	t.Parallel()
 	// Arrange
 	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
-
-	schema := &Schema{}
-
-	registry.schemaIDs = map[int]*Schema{
-		234: schema,
+	_, err := registry.CreateSchemaNormalized("cupcake", `{"type": "record", "name": "cupcake", "fields": []}`, Avro)
+	if err != nil {
+		t.Fatal(err)
 	}
 
 	// Act
-	result, err := registry.GetSchema(234)
-
-	// Assert
-	assert.Nil(t, err)
-	assert.Same(t, schema, result)
-}
-
-func TestMockSchemaRegistryClient_GetSchema_ReturnsErrOnNotFound(t *testing.T) {
-	t.Parallel()
-	// Arrange
-	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
-
-	// Act
-	result, err := registry.GetSchema(234)
+	schema, err := registry.CreateSchemaNormalized("cupcake", `{
+		"name": "cupcake",
+		"fields": [],
+		"type": "record"
+	}`, Avro)
 
 	// Assert
-	assert.ErrorIs(t, err, errSchemaNotFound)
-
-	assert.Nil(t, result)
+	assert.Nil(t, schema)
+	assert.ErrorIs(t, err, errSchemaAlreadyRegistered)
 }
 
-func TestMockSchemaRegistryClient_GetSubjectVersionsById_ReturnsSubjectVersions(t *testing.T) {
+func TestMockSchemaRegistryClient_LookupSchemaNormalized_FindsReformattedSchema(t *testing.T) {
 	t.Parallel()
 	// Arrange
 	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
-	registry.schemaVersions["cupcake"] = map[int]*Schema{
-		1: {id: 1, version: 1},
-		2: {id: 2, version: 2},
-		3: {id: 3, version: 3},
+	created, err := registry.CreateSchema("cupcake", `{"type": "record", "name": "cupcake", "fields": []}`, Avro)
+	if err != nil {
+		t.Fatal(err)
 	}
-	registry.schemaVersions["bakery"] = map[int]*Schema{
-		1: {id: 4, version: 1},
-		2: {id: 5, version: 2},
-	}
-
-	// Act
-	result, err := registry.GetSubjectVersionsById(2)
-
-	// Assert
-	assert.Nil(t, err)
-
-	assert.Len(t, result, 3)
-	assert.Equal(t, "cupcake", result[0].Subject)
-	assert.Equal(t, "cupcake", result[1].Subject)
-	assert.Equal(t, "cupcake", result[2].Subject)
-}
-
-func TestMockSchemaRegistryClient_GetSubjectVersionsById_ReturnsErrOnNotFound(t *testing.T) {
-	t.Parallel()
-	// Arrange
-	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
 
 	// Act
-	result, err := registry.GetSubjectVersionsById(2)
+	found, err := registry.LookupSchemaNormalized("cupcake", `{
+		"name": "cupcake",
+		"fields": [],
+		"type": "record"
+	}`, Avro)
+	_, notFoundErr := registry.LookupSchemaNormalized("cupcake", `"string"`, Avro)
+	_, subjectNotFoundErr := registry.LookupSchemaNormalized("bakery", `"string"`, Avro)
 
 	// Assert
-	assert.ErrorIs(t, err, errSchemaNotFound)
-
-	assert.Nil(t, result)
+	assert.NoError(t, err)
+	assert.Equal(t, created, found)
+	assert.ErrorIs(t, notFoundErr, errSchemaNotFound)
+	assert.ErrorIs(t, subjectNotFoundErr, errSchemaNotFound)
 }
 
-func TestMockSchemaRegistryClient_GetLatestSchema_ReturnsErrorOn0SchemaVersions(t *testing.T) {
+func TestMockSchemaRegistryClient_CreateSchemaWithConfig_StoresMetadataAndRuleSet(t *testing.T) {
 	t.Parallel()
 	// Arrange
 	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	metadata := &Metadata{Tags: map[string][]string{"flavor": {"PII"}}}
+	ruleSet := &RuleSet{DomainRules: []Rule{{Name: "rule1"}}}
 
 	// Act
-	result, err := registry.GetLatestSchema("cupcake")
+	created, err := registry.CreateSchemaWithConfig("cupcake", RegisterSchemaRequest{
+		Schema:     `"string"`,
+		SchemaType: Avro,
+		Metadata:   metadata,
+		RuleSet:    ruleSet,
+	})
 
 	// Assert
-	assert.Nil(t, result)
-	assert.ErrorIs(t, err, errSchemaNotFound)
+	assert.NoError(t, err)
+	schema, err := registry.GetSchema(created.ID())
+	assert.NoError(t, err)
+	assert.Equal(t, metadata, schema.Metadata())
+	assert.Equal(t, ruleSet, schema.RuleSet())
 }
 
-func TestMockSchemaRegistryClient_GetLatestSchema_ReturnsExpectedSchema(t *testing.T) {
+func TestMockSchemaRegistryClient_CreateSchemaFromFile_InfersSchemaType(t *testing.T) {
 	t.Parallel()
 	tests := map[string]struct {
-		subject         string
-		existingSchemas map[int]*Schema
-		expectedSchema  *Schema
+		filename     string
+		expectedType SchemaType
+		expectError  bool
 	}{
-		"cupcake": {
-			subject:         "cupcake",
-			existingSchemas: map[int]*Schema{23: {id: 23}},
-			expectedSchema:  &Schema{id: 23},
+		"avro": {
+			filename:     "cupcake.avsc",
+			expectedType: Avro,
 		},
-		"bakery": {
-			subject: "bakery",
-			existingSchemas: map[int]*Schema{
-				1: {id: 1},
-				2: {id: 2},
-				5: {id: 5},
-			},
-			expectedSchema: &Schema{id: 5},
+		"json": {
+			filename:     "cupcake.JSON",
+			expectedType: Json,
+		},
+		"unknown extension": {
+			filename:    "cupcake.txt",
+			expectError: true,
 		},
 	}
 
@@ -405,252 +470,1572 @@ func TestMockSchemaRegistryClient_GetLatestSchema_ReturnsExpectedSchema(t *testi
 			t.Parallel()
 			// Arrange
 			registry := CreateMockSchemaRegistryClient("http://localhost:8081")
-			registry.schemaVersions[testData.subject] = testData.existingSchemas
+			path := filepath.Join(t.TempDir(), testData.filename)
+			if err := ioutil.WriteFile(path, []byte(`"string"`), 0644); err != nil {
+				t.Fatal(err)
+			}
 
 			// Act
-			result, err := registry.GetLatestSchema(testData.subject)
+			schema, err := registry.CreateSchemaFromFile("cupcake", path)
 
 			// Assert
-			assert.Nil(t, err)
-
-			assert.Equal(t, testData.expectedSchema.id, result.id)
+			if testData.expectError {
+				assert.Nil(t, schema)
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, `"string"`, schema.Schema())
+			assert.Equal(t, testData.expectedType, *schema.SchemaType())
 		})
 	}
 }
 
-func TestMockSchemaRegistryClient_GetSchemaVersions_ReturnsSchemaVersions(t *testing.T) {
+func TestMockSchemaRegistryClient_CreateSchemaFromFileWithType_UsesGivenType(t *testing.T) {
 	t.Parallel()
 	// Arrange
 	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
-	registry.schemaVersions["cupcake"] = map[int]*Schema{
-		1: {id: 1},
-		2: {id: 2},
-		3: {id: 3},
+	path := filepath.Join(t.TempDir(), "cupcake.txt")
+	if err := ioutil.WriteFile(path, []byte(`"string"`), 0644); err != nil {
+		t.Fatal(err)
 	}
 
 	// Act
-	result, err := registry.GetSchemaVersions("cupcake")
+	schema, err := registry.CreateSchemaFromFileWithType("cupcake", path, Avro)
 
 	// Assert
-	assert.Nil(t, err)
-
-	assert.Equal(t, []int{1, 2, 3}, result)
+	assert.NoError(t, err)
+	assert.Equal(t, Avro, *schema.SchemaType())
 }
 
-func TestMockSchemaRegistryClient_GetSchemaByVersion_ReturnsErrorOnSubjectNotFound(t *testing.T) {
+func TestMockSchemaRegistryClient_CreateSchemaWithCompatibility_SetsSubjectLevel(t *testing.T) {
 	t.Parallel()
 	// Arrange
 	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
 
 	// Act
-	result, err := registry.GetSchemaByVersion("cupcake", 0)
+	_, err := registry.CreateSchemaWithCompatibility("cupcake", `"string"`, Avro, FullTransitive)
 
 	// Assert
-	assert.Nil(t, result)
-	assert.ErrorIs(t, err, errSubjectNotFound)
+	assert.NoError(t, err)
+	level, err := registry.GetCompatibilityLevel("cupcake", false)
+	assert.NoError(t, err)
+	assert.Equal(t, FullTransitive, *level)
 }
 
-func TestMockSchemaRegistryClient_GetSchemaByVersion_ReturnsErrorOnSchemaNotFound(t *testing.T) {
+func TestMockSchemaRegistryClient_GetEffectiveCompatibility(t *testing.T) {
 	t.Parallel()
 	// Arrange
 	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
-	registry.schemaVersions = map[string]map[int]*Schema{
-		"cupcake": {},
-	}
+	_, _ = registry.ChangeSubjectCompatibilityLevel("cupcake", Full)
 
 	// Act
-	result, err := registry.GetSchemaByVersion("cupcake", 0)
+	explicit, explicitInherited, explicitErr := registry.GetEffectiveCompatibility("cupcake")
+	global, globalInherited, globalErr := registry.GetEffectiveCompatibility("bakery")
 
 	// Assert
-	assert.Nil(t, result)
-	assert.ErrorIs(t, err, errSchemaNotFound)
+	assert.NoError(t, explicitErr)
+	assert.Equal(t, Full, explicit)
+	assert.False(t, explicitInherited)
+	assert.NoError(t, globalErr)
+	assert.Equal(t, Backward, global)
+	assert.True(t, globalInherited)
 }
 
-func TestMockSchemaRegistryClient_GetSchemaByVersion_ReturnsSchema(t *testing.T) {
+func TestMockSchemaRegistryClient_CreateSchemaAndListVersions(t *testing.T) {
 	t.Parallel()
-	tests := map[string]struct {
-		subject string
-		version int
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	_, _ = registry.CreateSchema("cupcake", `"string"`, Avro)
+	_, _ = registry.CreateSchema("cupcake", `"int"`, Avro)
 
-		existingSchemas map[int]*Schema
-		expectedSchema  *Schema
-	}{
-		"cupcake": {
-			subject: "cupcake",
-			version: 23,
+	// Act
+	schema, versions, err := registry.CreateSchemaAndListVersions("cupcake", `"long"`, Avro)
 
-			existingSchemas: map[int]*Schema{23: {id: 1}},
-			expectedSchema:  &Schema{id: 1},
-		},
-		"bakery": {
-			subject: "bakery",
-			version: 2,
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, schema.Version())
+	assert.Equal(t, []int{1, 2, 3}, versions)
+}
 
-			existingSchemas: map[int]*Schema{
-				1: {id: 4},
-				2: {id: 5},
-				5: {id: 6},
-			},
-			expectedSchema: &Schema{id: 5},
-		},
-	}
+func TestMockSchemaRegistryClient_CreateSchemaIdempotent_ReportsExistingSchemas(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
 
-	for name, testData := range tests {
-		testData := testData
-		t.Run(name, func(t *testing.T) {
-			t.Parallel()
-			// Arrange
-			registry := CreateMockSchemaRegistryClient("http://localhost:8081")
-			registry.schemaVersions[testData.subject] = testData.existingSchemas
+	// Act
+	first, firstCreated, firstErr := registry.CreateSchemaIdempotent("cupcake", testSchema1, Avro)
+	second, secondCreated, secondErr := registry.CreateSchemaIdempotent("cupcake", testSchema1, Avro)
 
-			// Act
-			result, err := registry.GetSchemaByVersion(testData.subject, testData.version)
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.True(t, firstCreated)
+	assert.NoError(t, secondErr)
+	assert.False(t, secondCreated)
+	assert.Equal(t, first, second)
+	assert.Len(t, registry.schemaVersions["cupcake"], 1)
+}
 
-			// Assert
-			assert.Nil(t, err)
+func TestMockSchemaRegistryClient_SubjectExists(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	_, _ = registry.CreateSchema("cupcake", `"string"`, Avro)
 
-			assert.Equal(t, testData.expectedSchema.id, result.id)
-		})
-	}
+	// Act
+	cupcakeExists, cupcakeErr := registry.SubjectExists("cupcake")
+	bakeryExists, bakeryErr := registry.SubjectExists("bakery")
+
+	// Assert
+	assert.NoError(t, cupcakeErr)
+	assert.True(t, cupcakeExists)
+	assert.NoError(t, bakeryErr)
+	assert.False(t, bakeryExists)
 }
 
-func TestMockSchemaRegistryClient_GetSubjects_ReturnsAllSubjects(t *testing.T) {
+func TestMockSchemaRegistryClient_ReimportSubject_PreservesVersionOrder(t *testing.T) {
 	t.Parallel()
 	// Arrange
-	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
-	registry.schemaVersions = map[string]map[int]*Schema{
-		"1": {},
-		"2": {},
-		"3": {},
+	source := CreateMockSchemaRegistryClient("http://localhost:8081")
+	target := CreateMockSchemaRegistryClient("http://localhost:8082")
+	for _, schema := range []string{`"string"`, `"int"`, `"long"`} {
+		if _, err := source.CreateSchema("cupcake", schema, Avro); err != nil {
+			t.Fatal(err)
+		}
 	}
+	// Make the target assign other IDs than the source
+	_, _ = target.CreateSchema("bakery", `"bytes"`, Avro)
 
 	// Act
-	result, err := registry.GetSubjects()
+	err := source.ReimportSubject(target, "cupcake")
 
 	// Assert
-	assert.Nil(t, err)
-	assert.Contains(t, result, "1")
-	assert.Contains(t, result, "2")
-	assert.Contains(t, result, "3")
+	assert.NoError(t, err)
+	reimported, err := target.GetAllVersionsForSubject("cupcake")
+	assert.NoError(t, err)
+	require.Len(t, reimported, 3)
+	for i, schema := range []string{`"string"`, `"int"`, `"long"`} {
+		assert.Equal(t, i+1, reimported[i].Version())
+		assert.Equal(t, schema, reimported[i].Schema())
+	}
 }
 
-func TestMockSchemaRegistryClient_GetSubjectsIncludingDeleted_IsNotImplemented(t *testing.T) {
+func TestMockSchemaRegistryClient_ReimportSubject_StopsAtFirstRejectedVersion(t *testing.T) {
 	t.Parallel()
 	// Arrange
-	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	source := CreateMockSchemaRegistryClient("http://localhost:8081")
+	target := CreateMockSchemaRegistryClient("http://localhost:8082")
+	_, _ = source.CreateSchema("cupcake", `"string"`, Avro)
+	_, _ = source.CreateSchema("cupcake", `"int"`, Avro)
+	_, _ = target.CreateSchema("cupcake", `"string"`, Avro)
 
 	// Act
-	result, err := registry.GetSubjectsIncludingDeleted()
+	err := source.ReimportSubject(target, "cupcake")
 
 	// Assert
-	assert.Nil(t, result)
-	assert.ErrorIs(t, err, errNotImplemented)
+	assert.ErrorIs(t, err, errSchemaAlreadyRegistered)
+	assert.Contains(t, err.Error(), "failed to reimport version 1 of cupcake")
+	versions, _ := target.GetSchemaVersions("cupcake")
+	assert.Equal(t, []int{1}, versions)
 }
 
-func TestMockSchemaRegistryClient_DeleteSubject_DeletesSubject(t *testing.T) {
+func TestMockSchemaRegistryClient_FindSchemaVersion(t *testing.T) {
 	t.Parallel()
+	// Arrange
 	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
-	registry.schemaVersions = map[string]map[int]*Schema{
-		"b": {},
+	_, err := registry.CreateSchema("cupcake", `"string"`, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := registry.CreateSchema("cupcake", `"int"`, Avro)
+	if err != nil {
+		t.Fatal(err)
 	}
 
 	// Act
-	err := registry.DeleteSubject("b", false)
+	version, id, err := registry.FindSchemaVersion("cupcake", `"int"`, Avro)
+	_, _, notFoundErr := registry.FindSchemaVersion("cupcake", `"long"`, Avro)
 
 	// Assert
-	assert.Nil(t, err)
-	assert.Equal(t, map[string]map[int]*Schema{}, registry.schemaVersions)
+	assert.NoError(t, err)
+	assert.Equal(t, created.Version(), version)
+	assert.Equal(t, created.ID(), id)
+	assert.ErrorIs(t, notFoundErr, errSchemaNotFound)
 }
 
-func TestMockSchemaRegistryClient_DeleteSubjectByVersion_DeletesSubjectVersion(t *testing.T) {
+func TestMockSchemaRegistryClient_GetSchemasByIDs_ReturnsFoundSchemas(t *testing.T) {
 	t.Parallel()
+	// Arrange
 	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
-	registry.schemaVersions = map[string]map[int]*Schema{
-		"b": {
-			1: {id: 1},
-			2: {id: 2},
-			3: {id: 3},
-		},
+	created, err := registry.CreateSchema("cupcake", `"string"`, Avro)
+	if err != nil {
+		t.Fatal(err)
 	}
 
 	// Act
-	err := registry.DeleteSubjectByVersion("b", 2, false)
+	schemas, err := registry.GetSchemasByIDs([]int{created.ID(), created.ID() + 1})
 
 	// Assert
-	assert.Nil(t, err)
-	if assert.NotNil(t, registry.schemaVersions["b"]) {
-		assert.Nil(t, registry.schemaVersions["b"][2])
+	assert.Equal(t, map[int]*Schema{created.ID(): created}, schemas)
+	var schemasErr *SchemasByIDsError
+	if assert.ErrorAs(t, err, &schemasErr) {
+		assert.ErrorIs(t, schemasErr.Errors[created.ID()+1], errSchemaNotFound)
 	}
 }
 
-func TestMockSchemaRegistryClient_DeleteSubjectByVersion_ReturnsErrorOnSubjectNotFound(t *testing.T) {
+func TestMockSchemaRegistryClient_GetCompatibilityLevels_ReturnsFoundLevels(t *testing.T) {
 	t.Parallel()
+	// Arrange
 	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	registry.subjectCompatibilities = map[string]CompatibilityLevel{"cupcake": Full}
+	registry.globalCompatibility = Backward
 
 	// Act
-	err := registry.DeleteSubjectByVersion("cupcake", 5, false)
+	levels, err := registry.GetCompatibilityLevels([]string{"cupcake", "bakery"}, false)
+	defaulted, defaultedErr := registry.GetCompatibilityLevels([]string{"cupcake", "bakery"}, true)
 
 	// Assert
-	assert.ErrorIs(t, err, errSubjectNotFound)
+	assert.Equal(t, map[string]CompatibilityLevel{"cupcake": Full}, levels)
+	var levelsErr *CompatibilityLevelsError
+	if assert.ErrorAs(t, err, &levelsErr) {
+		assert.ErrorIs(t, levelsErr.Errors["bakery"], errCompatibilityNotFound)
+	}
+	assert.NoError(t, defaultedErr)
+	assert.Equal(t, map[string]CompatibilityLevel{"cupcake": Full, "bakery": Backward}, defaulted)
 }
 
-func TestMockSchemaRegistryClient_DeleteSubjectByVersion_ReturnsErrorOnVersionNotFound(t *testing.T) {
+func TestMockSchemaRegistryClient_GetRawSchema_ReturnsSchemaString(t *testing.T) {
 	t.Parallel()
+	// Arrange
 	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
-	registry.schemaVersions = map[string]map[int]*Schema{
-		"cupcake": {
-			1: {id: 1},
-			3: {id: 3},
-		},
+	created, err := registry.CreateSchema("cupcake", `"string"`, Avro)
+	if err != nil {
+		t.Fatal(err)
 	}
 
 	// Act
-	err := registry.DeleteSubjectByVersion("cupcake", 5, false)
+	schema, err := registry.GetRawSchema(created.ID())
+	_, notFoundErr := registry.GetRawSchema(created.ID() + 1)
 
 	// Assert
-	assert.ErrorIs(t, err, errSchemaNotFound)
+	assert.NoError(t, err)
+	assert.Equal(t, `"string"`, schema)
+	assert.ErrorIs(t, notFoundErr, errSchemaNotFound)
 }
 
-func TestMockSchemaRegistryClient_ChangeSubjectCompatibilityLevel_IsNotImplemented(t *testing.T) {
+func TestMockSchemaRegistryClient_GetLatestSchemaMetadata_ReturnsLatestVersion(t *testing.T) {
 	t.Parallel()
 	// Arrange
 	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	_, err := registry.CreateSchema("cupcake", `"string"`, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest, err := registry.CreateSchema("cupcake", `"int"`, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Act
-	result, err := registry.ChangeSubjectCompatibilityLevel("", "")
+	metadata, err := registry.GetLatestSchemaMetadata("cupcake")
 
 	// Assert
-	assert.Nil(t, result)
-	assert.ErrorIs(t, err, errNotImplemented)
+	assert.NoError(t, err)
+	assert.Equal(t, "cupcake", metadata.Subject)
+	assert.Equal(t, latest.ID(), metadata.ID)
+	assert.Equal(t, latest.Version(), metadata.Version)
+	assert.Equal(t, Avro, metadata.SchemaType)
+	assert.Equal(t, `"int"`, metadata.Schema)
 }
 
-func TestMockSchemaRegistryClient_GetGlobalCompatibilityLevel_IsNotImplemented(t *testing.T) {
+func TestMockSchemaRegistryClient_StreamSubjects_StopsOnError(t *testing.T) {
 	t.Parallel()
 	// Arrange
 	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	for _, subject := range []string{"cupcake", "bakery"} {
+		if _, err := registry.CreateSchema(subject, `"string"`, Avro); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var subjects []string
+	stop := errors.New("stop")
 
 	// Act
-	result, err := registry.GetGlobalCompatibilityLevel()
+	err := registry.StreamSubjects(func(subject string) error {
+		subjects = append(subjects, subject)
+		return stop
+	})
 
 	// Assert
-	assert.Nil(t, result)
-	assert.ErrorIs(t, err, errNotImplemented)
+	assert.Equal(t, stop, err)
+	assert.Equal(t, []string{"bakery"}, subjects)
 }
 
-func TestMockSchemaRegistryClient_GetCompatibilityLevel_IsNotImplemented(t *testing.T) {
+func TestMockSchemaRegistryClient_GetSubjectsByPrefix_FiltersSubjects(t *testing.T) {
 	t.Parallel()
 	// Arrange
 	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	for _, subject := range []string{"orders-eu-value", "orders-us-value", "payments-value"} {
+		if _, err := registry.CreateSchema(subject, `"string"`, Avro); err != nil {
+			t.Fatal(err)
+		}
+	}
 
 	// Act
-	result, err := registry.GetCompatibilityLevel("", false)
+	subjects, err := registry.GetSubjectsByPrefix("orders-", false)
 
 	// Assert
-	assert.Nil(t, result)
-	assert.ErrorIs(t, err, errNotImplemented)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"orders-eu-value", "orders-us-value"}, subjects)
 }
 
-func TestMockSchemaRegistryClient_IsSchemaCompatible_IsNotImplemented(t *testing.T) {
+func TestMockSchemaRegistryClient_GetSubjectsByPrefix_IncludesSoftDeletedSubjects(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	_, _ = registry.CreateSchema("orders-eu-value", `"string"`, Avro)
+	_, _ = registry.CreateSchema("orders-us-value", `"string"`, Avro)
+	_ = registry.DeleteSubjectByVersion("orders-us-value", 1, false)
+
+	// Act
+	subjects, err := registry.GetSubjectsByPrefix("orders-", false)
+	withDeleted, withDeletedErr := registry.GetSubjectsByPrefix("orders-", true)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"orders-eu-value"}, subjects)
+	assert.NoError(t, withDeletedErr)
+	assert.ElementsMatch(t, []string{"orders-eu-value", "orders-us-value"}, withDeleted)
+}
+
+func TestMockSchemaRegistryClient_DeleteAllSubjects_DeletesUnlessDryRun(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	for _, subject := range []string{"cupcake", "bakery"} {
+		if _, err := registry.CreateSchema(subject, `"string"`, Avro); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Act
+	dryRunSubjects, dryRunErr := registry.DeleteAllSubjects(false, true)
+	remainingAfterDryRun, _ := registry.GetSubjects()
+	deletedSubjects, err := registry.DeleteAllSubjects(false, false)
+	remaining, _ := registry.GetSubjects()
+
+	// Assert
+	assert.NoError(t, dryRunErr)
+	assert.Equal(t, []string{"bakery", "cupcake"}, dryRunSubjects)
+	assert.Len(t, remainingAfterDryRun, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bakery", "cupcake"}, deletedSubjects)
+	assert.Empty(t, remaining)
+}
+
+func TestMockSchemaRegistryClient_DeleteSchemaByID_DeletesAllVersions(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	created, err := registry.CreateSchema("cupcake", `"string"`, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.SetSchema(created.ID(), "bakery", `"string"`, Avro, -1); err != nil {
+		t.Fatal(err)
+	}
+	other, err := registry.CreateSchema("bakery", `"int"`, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	err = registry.DeleteSchemaByID(created.ID(), false)
+
+	// Assert
+	assert.NoError(t, err)
+	_, getErr := registry.GetSchema(created.ID())
+	assert.ErrorIs(t, getErr, errSchemaNotFound)
+	assert.NotContains(t, registry.schemaVersions, "cupcake")
+	assert.Equal(t, map[int]*Schema{other.Version(): other}, registry.schemaVersions["bakery"])
+	assert.ErrorIs(t, registry.DeleteSchemaByID(created.ID(), false), errSchemaNotFound)
+	deleted, deletedErr := registry.GetSchemaByVersionIncludingDeleted("cupcake", created.Version())
+	assert.NoError(t, deletedErr)
+	assert.Equal(t, created, deleted)
+}
+
+func TestMockSchemaRegistryClient_DeleteSchemaByID_DeletesPermanently(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	created, err := registry.CreateSchema("cupcake", `"string"`, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	err = registry.DeleteSchemaByID(created.ID(), true)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotContains(t, registry.schemaVersions, "cupcake")
+	_, deletedErr := registry.GetSchemaByVersionIncludingDeleted("cupcake", created.Version())
+	assert.Error(t, deletedErr)
+	subjects, err := registry.GetSubjects()
+	assert.NoError(t, err)
+	assert.Empty(t, subjects)
+}
+
+func TestMockSchemaRegistryClient_GetAllVersionsForSubject_ReturnsSortedVersions(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	var created []*Schema
+	for _, schema := range []string{`"string"`, `"int"`, `"long"`} {
+		version, err := registry.CreateSchema("cupcake", schema, Avro)
+		if err != nil {
+			t.Fatal(err)
+		}
+		created = append(created, version)
+	}
+
+	// Act
+	schemas, err := registry.GetAllVersionsForSubject("cupcake")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, created, schemas)
+}
+
+func TestMockSchemaRegistryClient_GetSchemaExpanded_InlinesReferences(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	flavor := &Schema{id: 1, version: 1, schemaType: &avroType,
+		schema: `{"type":"enum","name":"Flavor","namespace":"com.bakery","symbols":["VANILLA"]}`}
+	topping := &Schema{id: 2, version: 1, schemaType: &avroType,
+		schema:     `{"type":"record","name":"Topping","namespace":"com.bakery","fields":[{"name":"flavor","type":"Flavor"}]}`,
+		references: []Reference{{Name: "com.bakery.Flavor", Subject: "flavor", Version: 1}}}
+	cupcake := &Schema{id: 3, version: 1, schemaType: &avroType,
+		schema: `{"type":"record","name":"Cupcake","namespace":"com.bakery","fields":[` +
+			`{"name":"flavor","type":"com.bakery.Flavor"},{"name":"toppings","type":{"type":"array","items":"Topping"}}]}`,
+		references: []Reference{
+			{Name: "com.bakery.Flavor", Subject: "flavor", Version: 1},
+			{Name: "com.bakery.Topping", Subject: "topping", Version: 1},
+		}}
+	registry.schemaIDs = map[int]*Schema{1: flavor, 2: topping, 3: cupcake}
+	registry.schemaVersions = map[string]map[int]*Schema{
+		"flavor":  {1: flavor},
+		"topping": {1: topping},
+		"cupcake": {1: cupcake},
+	}
+
+	// Act
+	expanded, err := registry.GetSchemaExpanded(cupcake.ID())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"record","name":"Cupcake","namespace":"com.bakery","fields":[
+		{"name":"flavor","type":{"type":"enum","name":"Flavor","namespace":"com.bakery","symbols":["VANILLA"]}},
+		{"name":"toppings","type":{"type":"array","items":
+			{"type":"record","name":"Topping","namespace":"com.bakery","fields":[{"name":"flavor","type":"Flavor"}]}}}
+	]}`, expanded)
+	_, err = goavro.NewCodec(expanded)
+	assert.NoError(t, err)
+}
+
+func TestMockSchemaRegistryClient_GetSchemaFormat_ResolvesReferences(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	flavor := &Schema{id: 1, version: 1, schemaType: &avroType,
+		schema: `{"type":"enum","name":"Flavor","symbols":["VANILLA"]}`}
+	cupcake := &Schema{id: 2, version: 1, schemaType: &avroType,
+		schema:     `{"type":"record","name":"Cupcake","fields":[{"name":"flavor","type":"Flavor"}]}`,
+		references: []Reference{{Name: "Flavor", Subject: "flavor", Version: 1}}}
+	registry.schemaIDs = map[int]*Schema{1: flavor, 2: cupcake}
+	registry.schemaVersions = map[string]map[int]*Schema{
+		"flavor":  {1: flavor},
+		"cupcake": {1: cupcake},
+	}
+
+	// Act
+	resolved, resolvedErr := registry.GetSchemaFormat(cupcake.ID(), "resolved")
+	serialized, serializedErr := registry.GetSchemaFormat(cupcake.ID(), "serialized")
+
+	// Assert
+	assert.NoError(t, resolvedErr)
+	assert.Equal(t, cupcake.ID(), resolved.ID())
+	assert.JSONEq(t, `{"type":"record","name":"Cupcake","fields":[`+
+		`{"name":"flavor","type":{"type":"enum","name":"Flavor","symbols":["VANILLA"]}}]}`, resolved.Schema())
+	assert.Nil(t, serialized)
+	assert.ErrorIs(t, serializedErr, errNotImplemented)
+}
+
+func TestMockSchemaRegistryClient_GetLatestSchemaWithReferences_ResolvesTransitively(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	flavor := &Schema{id: 1, version: 1, schemaType: &avroType,
+		schema: `{"type":"enum","name":"Flavor","symbols":["VANILLA"]}`}
+	topping := &Schema{id: 2, version: 1, schemaType: &avroType,
+		schema:     `{"type":"record","name":"Topping","fields":[{"name":"flavor","type":"Flavor"}]}`,
+		references: []Reference{{Name: "Flavor", Subject: "flavor", Version: 1}}}
+	cupcake := &Schema{id: 3, version: 1, schemaType: &avroType,
+		schema:     `{"type":"record","name":"Cupcake","fields":[{"name":"flavor","type":"Flavor"},{"name":"topping","type":"Topping"}]}`,
+		references: []Reference{{Name: "Flavor", Subject: "flavor", Version: 1}, {Name: "Topping", Subject: "topping", Version: 1}}}
+	registry.schemaIDs = map[int]*Schema{1: flavor, 2: topping, 3: cupcake}
+	registry.schemaVersions = map[string]map[int]*Schema{
+		"flavor":  {1: flavor},
+		"topping": {1: topping},
+		"cupcake": {1: cupcake},
+	}
+
+	// Act
+	schema, references, err := registry.GetLatestSchemaWithReferences("cupcake")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, cupcake, schema)
+	assert.Equal(t, map[string]*Schema{"Flavor": flavor, "Topping": topping}, references)
+}
+
+func TestMockSchemaRegistryClient_GetLatestSchemaWithReferences_ReturnsErrorOnMissingReference(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	cupcake := &Schema{id: 1, version: 1, schemaType: &avroType,
+		schema:     `{"type":"record","name":"Cupcake","fields":[{"name":"flavor","type":"Flavor"}]}`,
+		references: []Reference{{Name: "Flavor", Subject: "flavor", Version: 1}}}
+	registry.schemaIDs = map[int]*Schema{1: cupcake}
+	registry.schemaVersions = map[string]map[int]*Schema{"cupcake": {1: cupcake}}
+
+	// Act
+	schema, references, err := registry.GetLatestSchemaWithReferences("cupcake")
+
+	// Assert
+	assert.Nil(t, schema)
+	assert.Nil(t, references)
+	assert.ErrorIs(t, err, ErrSubjectNotFound)
+}
+
+func TestMockSchemaRegistryClient_GetSchemaExpanded_ReturnsErrorOnCycle(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	first := &Schema{id: 1, version: 1, schemaType: &avroType, schema: `{"type":"record","name":"First","fields":[]}`,
+		references: []Reference{{Name: "Second", Subject: "second", Version: 1}}}
+	second := &Schema{id: 2, version: 1, schemaType: &avroType, schema: `{"type":"record","name":"Second","fields":[]}`,
+		references: []Reference{{Name: "First", Subject: "first", Version: 1}}}
+	registry.schemaIDs = map[int]*Schema{1: first, 2: second}
+	registry.schemaVersions = map[string]map[int]*Schema{
+		"first":  {1: first},
+		"second": {1: second},
+	}
+
+	// Act
+	expanded, err := registry.GetSchemaExpanded(first.ID())
+
+	// Assert
+	assert.Empty(t, expanded)
+	assert.ErrorIs(t, err, ErrReferenceCycle)
+}
+
+func TestMockSchemaRegistryClient_DiffSchemaVersions_ListsChangedFields(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	_, err := registry.CreateSchema("cupcake", `{"type":"record","name":"cupcake","fields":[`+
+		`{"name":"flavor","type":"string"},{"name":"size","type":"int","default":1},{"name":"topping","type":"string"}]}`, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = registry.CreateSchema("cupcake", `{"type":"record","name":"cupcake","fields":[`+
+		`{"name":"flavor","type":["null","string"],"default":null},{"name":"size","type":"int","default":2},`+
+		`{"name":"price","type":"double","default":0.0}]}`, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	diff, err := registry.DiffSchemaVersions("cupcake", 1, 2)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []SchemaField{{Name: "price", Type: "double", Default: json.RawMessage(`0`)}}, diff.Added)
+	assert.Equal(t, []SchemaField{{Name: "topping", Type: "string"}}, diff.Removed)
+	assert.Equal(t, []FieldChange{
+		{
+			Name: "flavor",
+			Old:  SchemaField{Name: "flavor", Type: "string"},
+			New:  SchemaField{Name: "flavor", Type: `["null","string"]`, Default: json.RawMessage(`null`)},
+		},
+		{
+			Name: "size",
+			Old:  SchemaField{Name: "size", Type: "int", Default: json.RawMessage(`1`)},
+			New:  SchemaField{Name: "size", Type: "int", Default: json.RawMessage(`2`)},
+		},
+	}, diff.Modified)
+	assert.True(t, diff.Modified[0].TypeChanged())
+	assert.False(t, diff.Modified[1].TypeChanged())
+	assert.True(t, diff.Modified[1].DefaultChanged())
+}
+
+func TestMockSchemaRegistryClient_DiffSchemaVersions_ReturnsErrorOnUnsupportedSchemas(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	_, _ = registry.CreateSchema("flavor", `"string"`, Avro)
+	_, _ = registry.CreateSchema("flavor", `{"type":"enum","name":"Flavor","symbols":["VANILLA"]}`, Avro)
+
+	// Act
+	diff, err := registry.DiffSchemaVersions("flavor", 1, 2)
+
+	// Assert
+	assert.True(t, diff.IsEmpty())
+	assert.ErrorIs(t, err, ErrDiffUnsupported)
+}
+
+func TestMockSchemaRegistryClient_RecordsCredentials(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	// Act
+	registry.SetCredentials("user", "secret")
+	registry.SetBearerToken("token")
+
+	// Assert
+	username, password := registry.Credentials()
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "secret", password)
+	assert.Equal(t, "token", registry.BearerToken())
+}
+
+func TestMockSchemaRegistryClient_IsSchemaCompatibleWithAll_ChecksAllVersions(t *testing.T) {
+	t.Parallel()
+	const (
+		withTopping        = `{"type": "record", "name": "cupcake", "fields": [{"name": "flavor", "type": "string"}, {"name": "topping", "type": "string"}]}`
+		withDefaultTopping = `{"type": "record", "name": "cupcake", "fields": [{"name": "flavor", "type": "string"}, {"name": "topping", "type": "string", "default": "none"}]}`
+	)
+	tests := map[string]struct {
+		compatibility CompatibilityLevel
+		schema        string
+		expected      bool
+	}{
+		"backward with new field without default": {
+			compatibility: Backward,
+			schema:        withTopping,
+			expected:      false,
+		},
+		"backward with new field with default": {
+			compatibility: Backward,
+			schema:        withDefaultTopping,
+			expected:      true,
+		},
+		"forward with new field without default": {
+			compatibility: Forward,
+			schema:        withTopping,
+			expected:      true,
+		},
+		"forward without a field of an earlier version": {
+			compatibility: Forward,
+			schema:        `{"type": "record", "name": "cupcake", "fields": []}`,
+			expected:      false,
+		},
+		"none": {
+			compatibility: None,
+			schema:        `"string"`,
+			expected:      true,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+			if _, err := registry.CreateSchema("cupcake", testSchema1, Avro); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := registry.ChangeSubjectCompatibilityLevel("cupcake", testData.compatibility); err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			isCompatible, err := registry.IsSchemaCompatibleWithAll("cupcake", testData.schema, Avro)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expected, isCompatible)
+		})
+	}
+}
+
+func TestMockSchemaRegistryClient_IsSchemaCompatibleWithAll_ChecksLatestVersionUnlessTransitive(t *testing.T) {
+	t.Parallel()
+	const (
+		withDefaultTopping = `{"type": "record", "name": "cupcake", "fields": [{"name": "flavor", "type": "string"}, {"name": "topping", "type": "string", "default": "none"}]}`
+		withTopping        = `{"type": "record", "name": "cupcake", "fields": [{"name": "flavor", "type": "string"}, {"name": "topping", "type": "string"}]}`
+	)
+	tests := map[string]struct {
+		compatibility CompatibilityLevel
+		expected      bool
+	}{
+		"backward": {
+			compatibility: Backward,
+			expected:      true,
+		},
+		"backward transitive": {
+			compatibility: BackwardTransitive,
+			expected:      false,
+		},
+		"full": {
+			compatibility: Full,
+			expected:      true,
+		},
+		"full transitive": {
+			compatibility: FullTransitive,
+			expected:      false,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+			_, _ = registry.CreateSchema("cupcake", testSchema1, Avro)
+			_, _ = registry.CreateSchema("cupcake", withDefaultTopping, Avro)
+			_, _ = registry.ChangeSubjectCompatibilityLevel("cupcake", testData.compatibility)
+
+			// Act
+			// The topping can't be read from the first version, which doesn't have it
+			isCompatible, err := registry.IsSchemaCompatibleWithAll("cupcake", withTopping, Avro)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expected, isCompatible)
+		})
+	}
+}
+
+func TestMockSchemaRegistryClient_IsSchemaCompatibleWithAll_ReturnsErrorOnMissingSubject(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	// Act
+	isCompatible, err := registry.IsSchemaCompatibleWithAll("cupcake", testSchema1, Avro)
+
+	// Assert
+	assert.False(t, isCompatible)
+	assert.ErrorIs(t, err, errSubjectNotFound)
+}
+
+func TestMockSchemaRegistryClient_GetSchemaTypes_ReturnsAllTypes(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	// Act
+	schemaTypes, err := registry.GetSchemaTypes()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []SchemaType{Avro, Json, Protobuf}, schemaTypes)
+}
+
+func TestMockSchemaRegistryClient_RegisterSchemaWithUpdatedReferences_ReturnsErrorOnDanglingReference(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	if _, err := registry.CreateSchema("bakery", testSchema2, Avro); err != nil {
+		t.Fatal(err)
+	}
+	dangling := Reference{Name: "bakery", Subject: "bakery", Version: 2}
+
+	// Act
+	schema, err := registry.RegisterSchemaWithUpdatedReferences("cupcake", testSchema1, Avro,
+		[]Reference{{Name: "bakery", Subject: "bakery", Version: 1}, dangling})
+
+	// Assert
+	assert.Nil(t, schema)
+	var danglingErr *DanglingReferencesError
+	if assert.ErrorAs(t, err, &danglingErr) {
+		assert.Equal(t, []Reference{dangling}, danglingErr.References)
+	}
+}
+
+func TestMockSchemaRegistryClient_GetSchema_ReturnsSchema(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	schema := &Schema{}
+
+	registry.schemaIDs = map[int]*Schema{
+		234: schema,
+	}
+
+	// Act
+	result, err := registry.GetSchema(234)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Same(t, schema, result)
+}
+
+func TestMockSchemaRegistryClient_GetSchema_ReturnsErrOnNotFound(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	// Act
+	result, err := registry.GetSchema(234)
+
+	// Assert
+	assert.ErrorIs(t, err, errSchemaNotFound)
+
+	assert.Nil(t, result)
+}
+
+func TestMockSchemaRegistryClient_GetSubjectVersionsById_ReturnsSubjectVersions(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	registry.schemaVersions["cupcake"] = map[int]*Schema{
+		1: {id: 1, version: 1},
+		2: {id: 2, version: 2},
+		3: {id: 3, version: 3},
+	}
+	registry.schemaVersions["bakery"] = map[int]*Schema{
+		1: {id: 4, version: 1},
+		2: {id: 5, version: 2},
+	}
+
+	// Act
+	result, err := registry.GetSubjectVersionsById(2)
+
+	// Assert
+	assert.Nil(t, err)
+
+	assert.Len(t, result, 3)
+	assert.Equal(t, "cupcake", result[0].Subject)
+	assert.Equal(t, "cupcake", result[1].Subject)
+	assert.Equal(t, "cupcake", result[2].Subject)
+}
+
+func TestMockSchemaRegistryClient_GetSubjectVersionsById_ReturnsErrOnNotFound(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	// Act
+	result, err := registry.GetSubjectVersionsById(2)
+
+	// Assert
+	assert.ErrorIs(t, err, errSchemaNotFound)
+
+	assert.Nil(t, result)
+}
+
+func TestMockSchemaRegistryClient_GetLatestSchema_ReturnsErrorOn0SchemaVersions(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	// Act
+	result, err := registry.GetLatestSchema("cupcake")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, errSchemaNotFound)
+}
+
+func TestMockSchemaRegistryClient_GetLatestSchema_ReturnsExpectedSchema(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		subject         string
+		existingSchemas map[int]*Schema
+		expectedSchema  *Schema
+	}{
+		"cupcake": {
+			subject:         "cupcake",
+			existingSchemas: map[int]*Schema{23: {id: 23}},
+			expectedSchema:  &Schema{id: 23},
+		},
+		"bakery": {
+			subject: "bakery",
+			existingSchemas: map[int]*Schema{
+				1: {id: 1},
+				2: {id: 2},
+				5: {id: 5},
+			},
+			expectedSchema: &Schema{id: 5},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+			registry.schemaVersions[testData.subject] = testData.existingSchemas
+
+			// Act
+			result, err := registry.GetLatestSchema(testData.subject)
+
+			// Assert
+			assert.Nil(t, err)
+
+			assert.Equal(t, testData.expectedSchema.id, result.id)
+		})
+	}
+}
+
+func TestMockSchemaRegistryClient_GetSchemaVersions_ReturnsSchemaVersions(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	registry.schemaVersions["cupcake"] = map[int]*Schema{
+		1: {id: 1},
+		2: {id: 2},
+		3: {id: 3},
+	}
+
+	// Act
+	result, err := registry.GetSchemaVersions("cupcake")
+
+	// Assert
+	assert.Nil(t, err)
+
+	assert.Equal(t, []int{1, 2, 3}, result)
+}
+
+func TestMockSchemaRegistryClient_GetSchemaByVersion_ReturnsErrorOnSubjectNotFound(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	// Act
+	result, err := registry.GetSchemaByVersion("cupcake", 0)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, errSubjectNotFound)
+}
+
+func TestMockSchemaRegistryClient_GetSchemaByVersion_ReturnsErrorOnSchemaNotFound(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	registry.schemaVersions = map[string]map[int]*Schema{
+		"cupcake": {},
+	}
+
+	// Act
+	result, err := registry.GetSchemaByVersion("cupcake", 0)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, errSchemaNotFound)
+}
+
+func TestMockSchemaRegistryClient_GetSchemaByVersionString_ReturnsSchema(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	first, _ := registry.CreateSchema("cupcake", `"string"`, Avro)
+	second, _ := registry.CreateSchema("cupcake", `"int"`, Avro)
+
+	// Act
+	latest, latestErr := registry.GetSchemaByVersionString("cupcake", "latest")
+	numbered, numberedErr := registry.GetSchemaByVersionString("cupcake", "1")
+	_, invalidErr := registry.GetSchemaByVersionString("cupcake", "first")
+
+	// Assert
+	assert.NoError(t, latestErr)
+	assert.Equal(t, second.ID(), latest.ID())
+	assert.NoError(t, numberedErr)
+	assert.Equal(t, first.ID(), numbered.ID())
+	assert.Error(t, invalidErr)
+}
+
+func TestMockSchemaRegistryClient_GetSchemaByVersion_ReturnsSchema(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		subject string
+		version int
+
+		existingSchemas map[int]*Schema
+		expectedSchema  *Schema
+	}{
+		"cupcake": {
+			subject: "cupcake",
+			version: 23,
+
+			existingSchemas: map[int]*Schema{23: {id: 1}},
+			expectedSchema:  &Schema{id: 1},
+		},
+		"bakery": {
+			subject: "bakery",
+			version: 2,
+
+			existingSchemas: map[int]*Schema{
+				1: {id: 4},
+				2: {id: 5},
+				5: {id: 6},
+			},
+			expectedSchema: &Schema{id: 5},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+			registry.schemaVersions[testData.subject] = testData.existingSchemas
+
+			// Act
+			result, err := registry.GetSchemaByVersion(testData.subject, testData.version)
+
+			// Assert
+			assert.Nil(t, err)
+
+			assert.Equal(t, testData.expectedSchema.id, result.id)
+		})
+	}
+}
+
+func TestMockSchemaRegistryClient_GetReferencedBy_ReturnsReferencingSchemas(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	shared, err := registry.CreateSchema("shared", `"string"`, Avro)
+	assert.NoError(t, err)
+	reference := Reference{Name: "shared", Subject: "shared", Version: shared.version}
+	first, err := registry.CreateSchema("cupcake", testSchema1, Avro, reference)
+	assert.NoError(t, err)
+	second, err := registry.CreateSchema("bakery", testSchema2, Avro, reference)
+	assert.NoError(t, err)
+	_, err = registry.CreateSchema("unrelated", `"int"`, Avro)
+	assert.NoError(t, err)
+
+	// Act
+	result, err := registry.GetReferencedBy("shared", shared.version)
+	unreferenced, unreferencedErr := registry.GetReferencedBy("cupcake", first.version)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, []int{first.id, second.id}, result)
+	assert.Nil(t, unreferencedErr)
+	assert.Empty(t, unreferenced)
+}
+
+func TestMockSchemaRegistryClient_GetReferencedBy_ReturnsErrorOnSubjectNotFound(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	// Act
+	result, err := registry.GetReferencedBy("cupcake", 1)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, errSubjectNotFound)
+}
+
+func TestMockSchemaRegistryClient_GetSubjects_ReturnsAllSubjects(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	registry.schemaVersions = map[string]map[int]*Schema{
+		"1": {},
+		"2": {},
+		"3": {},
+	}
+
+	// Act
+	result, err := registry.GetSubjects()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Contains(t, result, "1")
+	assert.Contains(t, result, "2")
+	assert.Contains(t, result, "3")
+}
+
+func TestMockSchemaRegistryClient_Ping_ReturnsInjectedError(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	unreachable := errors.New("connection refused")
+
+	// Act
+	reachableErr := registry.Ping()
+	registry.SetPingError(unreachable)
+	unreachableErr := registry.Ping()
+
+	// Assert
+	assert.NoError(t, reachableErr)
+	assert.Equal(t, unreachable, unreachableErr)
+}
+
+func TestMockSchemaRegistryClient_GetMaxSchemaID_IncludesDeletedSchemas(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	_, _ = registry.CreateSchemaWithID("cupcake", `"string"`, Avro, 3, 1)
+	_, _ = registry.CreateSchemaWithID("bakery", `"int"`, Avro, 7, 1)
+	if err := registry.DeleteSubjectByVersion("bakery", 1, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	maxID, err := registry.GetMaxSchemaID()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 7, maxID)
+}
+
+func TestMockSchemaRegistryClient_ResolveVersionByContent(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	_, _ = registry.CreateSchema("cupcake", `{"type": "string"}`, Avro)
+	_, _ = registry.CreateSchema("cupcake", `{"type": "int"}`, Avro)
+
+	// Act
+	version, err := registry.ResolveVersionByContent("cupcake", "{\n\t\"type\":\"int\"\n}", Avro)
+	_, missingErr := registry.ResolveVersionByContent("cupcake", `"long"`, Avro)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version)
+	assert.ErrorIs(t, missingErr, ErrSchemaNotFound)
+}
+
+func TestMockSchemaRegistryClient_FindSubjectsWithSchema(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	_, _ = registry.CreateSchema("cupcake", `{"type": "string"}`, Avro)
+	_, _ = registry.CreateSchema("bakery", `"int"`, Avro)
+	_, _ = registry.CreateSchema("bakery", `{"type":"string"}`, Avro)
+
+	// Act
+	matches, err := registry.FindSubjectsWithSchema("{\n\t\"type\": \"string\"\n}", Avro)
+	none, noneErr := registry.FindSubjectsWithSchema(`"long"`, Avro)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, SubjectVersionResponse{
+		{Subject: "bakery", Version: 2},
+		{Subject: "cupcake", Version: 1},
+	}, matches)
+	assert.NoError(t, noneErr)
+	assert.Empty(t, none)
+}
+
+func TestMockSchemaRegistryClient_GetSubjectsWithContext_ReturnsErrorOnDoneContext(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	_, _ = registry.CreateSchema("cupcake", `"string"`, Avro)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	subjects, err := registry.GetSubjectsWithContext(ctx)
+	schemas, schemasErr := registry.GetAllSchemasWithContext(ctx, false, 0, 0)
+	_, schemaErr := registry.GetSchemaWithContext(ctx, 1)
+	_, latestErr := registry.GetLatestSchemaWithContext(ctx, "cupcake")
+	_, versionErr := registry.GetSchemaByVersionWithContext(ctx, "cupcake", 1)
+
+	// Assert
+	assert.Nil(t, subjects)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, schemas)
+	assert.ErrorIs(t, schemasErr, context.Canceled)
+	assert.ErrorIs(t, schemaErr, context.Canceled)
+	assert.ErrorIs(t, latestErr, context.Canceled)
+	assert.ErrorIs(t, versionErr, context.Canceled)
+}
+
+func TestMockSchemaRegistryClient_GetAllSchemas_ReturnsPagedSchemas(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		latestOnly bool
+		offset     int
+		limit      int
+
+		expectedIDs []int
+	}{
+		"all schemas": {
+			expectedIDs: []int{3, 1, 2},
+		},
+		"latest only": {
+			latestOnly:  true,
+			expectedIDs: []int{3, 2},
+		},
+		"offset and limit": {
+			offset:      1,
+			limit:       1,
+			expectedIDs: []int{1},
+		},
+		"offset past the end": {
+			offset:      5,
+			expectedIDs: []int{},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+			registry.schemaVersions = map[string]map[int]*Schema{
+				"cupcake": {
+					1: {id: 1, version: 1, schema: testSchema1, schemaType: &avroType},
+					2: {id: 2, version: 2, schema: testSchema2, schemaType: &avroType},
+				},
+				"bakery": {
+					1: {id: 3, version: 1, schema: testSchema2, schemaType: &avroType},
+				},
+			}
+
+			// Act
+			result, err := registry.GetAllSchemas(testData.latestOnly, testData.offset, testData.limit)
+
+			// Assert
+			assert.Nil(t, err)
+			ids := make([]int, 0, len(result))
+			for _, schema := range result {
+				ids = append(ids, schema.ID)
+			}
+			assert.Equal(t, testData.expectedIDs, ids)
+		})
+	}
+}
+
+func TestMockSchemaRegistryClient_GetSubjectsIncludingDeleted_IsNotImplemented(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	// Act
+	result, err := registry.GetSubjectsIncludingDeleted()
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, errNotImplemented)
+}
+
+func TestMockSchemaRegistryClient_DeleteSubject_DeletesSubject(t *testing.T) {
+	t.Parallel()
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	registry.schemaVersions = map[string]map[int]*Schema{
+		"b": {},
+	}
+
+	// Act
+	err := registry.DeleteSubject("b", false)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]map[int]*Schema{}, registry.schemaVersions)
+}
+
+func TestMockSchemaRegistryClient_DeleteSubjectByVersion_DeletesSubjectVersion(t *testing.T) {
+	t.Parallel()
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	registry.schemaVersions = map[string]map[int]*Schema{
+		"b": {
+			1: {id: 1},
+			2: {id: 2},
+			3: {id: 3},
+		},
+	}
+
+	// Act
+	err := registry.DeleteSubjectByVersion("b", 2, false)
+
+	// Assert
+	assert.Nil(t, err)
+	if assert.NotNil(t, registry.schemaVersions["b"]) {
+		assert.Nil(t, registry.schemaVersions["b"][2])
+	}
+}
+
+func TestMockSchemaRegistryClient_DeleteSubjectByVersion_ReturnsErrorOnSubjectNotFound(t *testing.T) {
+	t.Parallel()
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	// Act
+	err := registry.DeleteSubjectByVersion("cupcake", 5, false)
+
+	// Assert
+	assert.ErrorIs(t, err, errSubjectNotFound)
+}
+
+func TestMockSchemaRegistryClient_DeleteSubjectByVersion_ReturnsErrorOnVersionNotFound(t *testing.T) {
+	t.Parallel()
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	registry.schemaVersions = map[string]map[int]*Schema{
+		"cupcake": {
+			1: {id: 1},
+			3: {id: 3},
+		},
+	}
+
+	// Act
+	err := registry.DeleteSubjectByVersion("cupcake", 5, false)
+
+	// Assert
+	assert.ErrorIs(t, err, errSchemaNotFound)
+}
+
+func TestMockSchemaRegistryClient_DeleteSubjectVersionLatest_DeletesHighestVersion(t *testing.T) {
+	t.Parallel()
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	registry.schemaVersions = map[string]map[int]*Schema{
+		"cupcake": {
+			1: {id: 1, version: 1},
+			3: {id: 3, version: 3},
+		},
+	}
+
+	// Act
+	deleted, err := registry.DeleteSubjectVersionLatest("cupcake", false)
+	next, nextErr := registry.DeleteSubjectVersionLatest("cupcake", true)
+	_, emptyErr := registry.DeleteSubjectVersionLatest("cupcake", false)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, deleted)
+	assert.NotNil(t, registry.deletedVersions["cupcake"][3])
+	assert.NoError(t, nextErr)
+	assert.Equal(t, 1, next)
+	assert.Nil(t, registry.deletedVersions["cupcake"][1])
+	assert.ErrorIs(t, emptyErr, errSubjectNotFound)
+}
+
+func TestMockSchemaRegistryClient_GetSchemaByVersionIncludingDeleted_ReturnsSoftDeletedVersions(t *testing.T) {
+	t.Parallel()
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	registry.schemaVersions = map[string]map[int]*Schema{
+		"cupcake": {
+			1: {id: 1, version: 1},
+			2: {id: 2, version: 2},
+		},
+	}
+	if err := registry.DeleteSubjectByVersion("cupcake", 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.DeleteSubjectByVersion("cupcake", 2, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.DeleteSubjectByVersion("cupcake", 2, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	softDeleted, err := registry.GetSchemaByVersionIncludingDeleted("cupcake", 1)
+	_, liveErr := registry.GetSchemaByVersion("cupcake", 1)
+	_, permanentErr := registry.GetSchemaByVersionIncludingDeleted("cupcake", 2)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, softDeleted.ID())
+	assert.ErrorIs(t, liveErr, errSchemaNotFound)
+	assert.ErrorIs(t, permanentErr, errSchemaNotFound)
+}
+
+func TestMockSchemaRegistryClient_PurgeSubjectVersion_DeletesSubjectVersion(t *testing.T) {
+	t.Parallel()
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	registry.schemaVersions = map[string]map[int]*Schema{
+		"b": {
+			1: {id: 1},
+			2: {id: 2},
+		},
+	}
+
+	// Act
+	err := registry.PurgeSubjectVersion("b", 1)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Nil(t, registry.schemaVersions["b"][1])
+	assert.NotNil(t, registry.schemaVersions["b"][2])
+}
+
+func TestMockSchemaRegistryClient_ChangeSubjectCompatibilityLevel_SetsSubjectLevel(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	// Act
+	result, err := registry.ChangeSubjectCompatibilityLevel("cupcake", FullTransitive)
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, FullTransitive, *result)
+	assert.Equal(t, FullTransitive, registry.subjectCompatibilities["cupcake"])
+}
+
+func TestMockSchemaRegistryClient_GetGlobalCompatibilityLevel_DefaultsToBackward(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	// Act
+	result, err := registry.GetGlobalCompatibilityLevel()
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, Backward, *result)
+}
+
+func TestMockSchemaRegistryClient_GetConfig_ReturnsCompatibilityLevels(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	registry.subjectCompatibilities = map[string]CompatibilityLevel{"cupcake": Full}
+
+	// Act
+	globalConfig, err := registry.GetGlobalConfig()
+	subjectConfig, subjectErr := registry.GetSubjectConfig("cupcake")
+	_, missingErr := registry.GetSubjectConfig("bakery")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &RegistryConfig{CompatibilityLevel: Backward}, globalConfig)
+	assert.NoError(t, subjectErr)
+	assert.Equal(t, &RegistryConfig{CompatibilityLevel: Full}, subjectConfig)
+	assert.ErrorIs(t, missingErr, errCompatibilityNotFound)
+}
+
+func TestMockSchemaRegistryClient_UpdateGlobalConfig_UpdatesSetFields(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	normalize := true
+
+	// Act
+	_, err := registry.UpdateGlobalConfig(RegistryConfig{Normalize: &normalize, CompatibilityLevel: Full})
+	_, groupErr := registry.UpdateGlobalConfig(RegistryConfig{CompatibilityGroup: "application.major.version"})
+	globalConfig, globalErr := registry.GetGlobalConfig()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, groupErr)
+	assert.NoError(t, globalErr)
+	assert.Equal(t, &RegistryConfig{
+		Normalize:          &normalize,
+		CompatibilityLevel: Full,
+		CompatibilityGroup: "application.major.version",
+	}, globalConfig)
+}
+
+func TestMockSchemaRegistryClient_GetCompatibilityLevel_ReturnsExpectedLevel(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		subject         string
+		defaultToGlobal bool
+
+		expectedLevel CompatibilityLevel
+		expectedError error
+	}{
+		"subject level": {
+			subject:       "cupcake",
+			expectedLevel: Full,
+		},
+		"subject level over global": {
+			subject:         "cupcake",
+			defaultToGlobal: true,
+			expectedLevel:   Full,
+		},
+		"falls back to global": {
+			subject:         "bakery",
+			defaultToGlobal: true,
+			expectedLevel:   Forward,
+		},
+		"no subject level": {
+			subject:       "bakery",
+			expectedError: errCompatibilityNotFound,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			// Arrange
+			registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+			registry.globalCompatibility = Forward
+			registry.subjectCompatibilities["cupcake"] = Full
+
+			// Act
+			result, err := registry.GetCompatibilityLevel(testData.subject, testData.defaultToGlobal)
+
+			// Assert
+			if testData.expectedError != nil {
+				assert.Nil(t, result)
+				assert.ErrorIs(t, err, testData.expectedError)
+			} else if assert.Nil(t, err) {
+				assert.Equal(t, testData.expectedLevel, *result)
+			}
+		})
+	}
+}
+
+func TestMockSchemaRegistryClient_DeleteSubjectCompatibilityLevel_RevertsToGlobal(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	registry.subjectCompatibilities["cupcake"] = Full
+
+	// Act
+	result, err := registry.DeleteSubjectCompatibilityLevel("cupcake")
+
+	// Assert
+	assert.Nil(t, err)
+	assert.Equal(t, Backward, *result)
+	assert.NotContains(t, registry.subjectCompatibilities, "cupcake")
+}
+
+func TestMockSchemaRegistryClient_DeleteSubjectCompatibilityLevel_FallsBackToGlobal(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	_, err := registry.ChangeSubjectCompatibilityLevel("cupcake", None)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	reverted, err := registry.DeleteSubjectCompatibilityLevel("cupcake")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, Backward, *reverted)
+	effective, err := registry.GetCompatibilityLevel("cupcake", true)
+	assert.NoError(t, err)
+	assert.Equal(t, Backward, *effective)
+	_, err = registry.GetCompatibilityLevel("cupcake", false)
+	assert.ErrorIs(t, err, ErrCompatibilityNotConfigured)
+}
+
+func TestMockSchemaRegistryClient_IsSchemaCompatible_IsNotImplemented(t *testing.T) {
 	t.Parallel()
 	// Arrange
 	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
@@ -675,3 +2060,22 @@ func TestMockSchemaRegistryClient_LookupSchema_IsNotImplemented(t *testing.T) {
 	assert.Nil(t, result)
 	assert.ErrorIs(t, err, errNotImplemented)
 }
+
+func TestMockSchemaRegistryClient_Mode_IsNotImplemented(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+
+	// Act
+	globalMode, globalErr := registry.GetGlobalMode()
+	subjectMode, subjectErr := registry.GetMode("cupcake")
+	updatedMode, updateErr := registry.UpdateMode("cupcake", Import, true)
+
+	// Assert
+	assert.Empty(t, globalMode)
+	assert.ErrorIs(t, globalErr, errNotImplemented)
+	assert.Empty(t, subjectMode)
+	assert.ErrorIs(t, subjectErr, errNotImplemented)
+	assert.Empty(t, updatedMode)
+	assert.ErrorIs(t, updateErr, errNotImplemented)
+}
@@ -0,0 +1,13 @@
+module github.com/riferrei/srclient/protobuf
+
+go 1.13
+
+require (
+	github.com/jhump/protoreflect v1.14.1
+	github.com/riferrei/srclient v0.0.0
+	github.com/stretchr/testify v1.7.5
+	google.golang.org/protobuf v1.26.0
+)
+
+// The parser is developed along with srclient
+replace github.com/riferrei/srclient => ../
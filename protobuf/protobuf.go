@@ -0,0 +1,113 @@
+// Package protobuf parses the Protobuf schemas registered in Schema Registry
+// into file descriptors. It's a module of its own, apart from srclient, so
+// that only the applications importing it depend on a Protobuf parser.
+package protobuf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/riferrei/srclient"
+)
+
+// ErrNotProtobuf is returned when the schema, or one of its
+// references, isn't a Protobuf schema.
+var ErrNotProtobuf = errors.New("schema is not a Protobuf schema")
+
+// SchemaParser parses the Protobuf schemas fetched through
+// the client, caching the file descriptors by schema ID.
+type SchemaParser struct {
+	client      srclient.ISchemaRegistryClient
+	descriptors sync.Map
+}
+
+// NewSchemaParser creates a parser that fetches the schemas and their references through the client.
+func NewSchemaParser(client srclient.ISchemaRegistryClient) *SchemaParser {
+	return &SchemaParser{client: client}
+}
+
+// GetProtoSchema returns the file descriptor of the Protobuf schema with the given ID.
+// Its imports are resolved through the references of the schema, and of theirs, while
+// well-known types, like google/protobuf/timestamp.proto, are provided by the parser.
+// The context bounds fetching the schema and each of its references. Parsed schemas
+// are cached, as schema IDs never change what they point to.
+func (parser *SchemaParser) GetProtoSchema(ctx context.Context, id int) (*desc.FileDescriptor, error) {
+	if fileDescriptor, ok := parser.descriptors.Load(id); ok {
+		return fileDescriptor.(*desc.FileDescriptor), nil
+	}
+
+	schema, err := parser.client.GetSchemaWithContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkProtobuf(schema); err != nil {
+		return nil, err
+	}
+
+	filename := strconv.Itoa(id)
+	files := map[string]string{filename: schema.Schema()}
+	if err := parser.fetchReferences(ctx, schema.References(), files); err != nil {
+		return nil, err
+	}
+
+	fileDescriptors, err := protoparse.Parser{Accessor: protoparse.FileContentsFromMap(files)}.ParseFiles(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema %d: %w", id, err)
+	}
+	parser.descriptors.Store(id, fileDescriptors[0])
+	return fileDescriptors[0], nil
+}
+
+// ClearCache drops the parsed schemas.
+func (parser *SchemaParser) ClearCache() {
+	parser.descriptors.Range(func(key, _ interface{}) bool {
+		parser.descriptors.Delete(key)
+		return true
+	})
+}
+
+// fetchReferences adds the schemas referenced, and those they reference,
+// to the files keyed by the name they are imported with.
+func (parser *SchemaParser) fetchReferences(ctx context.Context, references []srclient.Reference, files map[string]string) error {
+	for _, reference := range references {
+		if _, ok := files[reference.Name]; ok {
+			continue
+		}
+		schema, err := parser.client.GetSchemaByVersionWithContext(ctx, reference.Subject, reference.Version)
+		if err != nil {
+			return fmt.Errorf("failed to fetch reference %s: %w", reference.Name, err)
+		}
+		if err := checkProtobuf(schema); err != nil {
+			return fmt.Errorf("reference %s: %w", reference.Name, err)
+		}
+		files[reference.Name] = schema.Schema()
+		if err := parser.fetchReferences(ctx, schema.References(), files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateSchema checks that the Protobuf schema can be parsed without sending it to
+// Schema Registry, returning an error matching srclient.ErrInvalidSchema if it can't.
+// Only well-known types can be imported, as the schemas referenced aren't fetched.
+func ValidateSchema(schema string) error {
+	const filename = "schema.proto"
+	accessor := protoparse.FileContentsFromMap(map[string]string{filename: schema})
+	if _, err := (protoparse.Parser{Accessor: accessor}).ParseFiles(filename); err != nil {
+		return fmt.Errorf("%w for %s: %v", srclient.ErrInvalidSchema, srclient.Protobuf, err)
+	}
+	return nil
+}
+
+func checkProtobuf(schema *srclient.Schema) error {
+	if schemaType := schema.SchemaType(); schemaType == nil || *schemaType != srclient.Protobuf {
+		return fmt.Errorf("%w: schema %d", ErrNotProtobuf, schema.ID())
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package protobuf
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/riferrei/srclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	orderSchema = `syntax = "proto3";
+package shop;
+
+import "common/money.proto";
+import "google/protobuf/timestamp.proto";
+
+message Order {
+  common.Money total = 1;
+  google.protobuf.Timestamp placed_at = 2;
+}`
+	moneySchema = `syntax = "proto3";
+package common;
+
+message Money {
+  string currency = 1;
+  int64 units = 2;
+}`
+)
+
+type schemaResponse struct {
+	Subject    string               `json:"subject"`
+	Version    int                  `json:"version"`
+	Schema     string               `json:"schema"`
+	SchemaType *srclient.SchemaType `json:"schemaType,omitempty"`
+	ID         int                  `json:"id"`
+	References []srclient.Reference `json:"references,omitempty"`
+}
+
+func newTestServer(t *testing.T, count *int32) *httptest.Server {
+	protobuf := srclient.Protobuf
+	avro := srclient.Avro
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var response schemaResponse
+		switch req.URL.String() {
+		case "/schemas/ids/1":
+			atomic.AddInt32(count, 1)
+			response = schemaResponse{Subject: "orders-value", Version: 1, Schema: orderSchema, SchemaType: &protobuf, ID: 1,
+				References: []srclient.Reference{{Name: "common/money.proto", Subject: "money", Version: 2}}}
+		case "/subjects/money/versions/2":
+			response = schemaResponse{Subject: "money", Version: 2, Schema: moneySchema, SchemaType: &protobuf, ID: 2}
+		case "/schemas/ids/3":
+			response = schemaResponse{Subject: "avro", Version: 1, Schema: `"string"`, SchemaType: &avro, ID: 3}
+		default:
+			require.Fail(t, "unhandled request")
+		}
+		body, _ := json.Marshal(response)
+		rw.Write(body)
+	}))
+}
+
+func TestSchemaParser_GetProtoSchema(t *testing.T) {
+	t.Parallel()
+	var count int32
+	server := newTestServer(t, &count)
+	defer server.Close()
+	client := srclient.CreateSchemaRegistryClient(server.URL)
+	client.CachingEnabled(false)
+	parser := NewSchemaParser(client)
+
+	for i := 0; i < 2; i++ {
+		fileDescriptor, err := parser.GetProtoSchema(context.Background(), 1)
+
+		assert.NoError(t, err)
+		order := fileDescriptor.FindMessage("shop.Order")
+		require.NotNil(t, order)
+		assert.Equal(t, "common.Money", order.FindFieldByName("total").GetMessageType().GetFullyQualifiedName())
+		assert.Equal(t, "google.protobuf.Timestamp", order.FindFieldByName("placed_at").GetMessageType().GetFullyQualifiedName())
+	}
+	// Test the parsed schema is cached
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+
+	parser.ClearCache()
+	_, err := parser.GetProtoSchema(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+}
+
+func TestSchemaParser_GetProtoSchemaReturnsErrors(t *testing.T) {
+	t.Parallel()
+	var count int32
+	server := newTestServer(t, &count)
+	defer server.Close()
+	client := srclient.CreateSchemaRegistryClient(server.URL)
+	client.CodecCreationEnabled(false)
+	parser := NewSchemaParser(client)
+
+	{
+		_, err := parser.GetProtoSchema(context.Background(), 3)
+		assert.ErrorIs(t, err, ErrNotProtobuf)
+	}
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := parser.GetProtoSchema(ctx, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+func TestSchemaParser_GetProtoSchemaBoundsReferencesByContext(t *testing.T) {
+	t.Parallel()
+	protobuf := srclient.Protobuf
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var response schemaResponse
+		switch req.URL.String() {
+		case "/schemas/ids/1":
+			response = schemaResponse{Subject: "orders-value", Version: 1, Schema: orderSchema, SchemaType: &protobuf, ID: 1,
+				References: []srclient.Reference{{Name: "common/money.proto", Subject: "money", Version: 2}}}
+		case "/subjects/money/versions/2":
+			<-release
+			return
+		default:
+			require.Fail(t, "unhandled request")
+		}
+		body, _ := json.Marshal(response)
+		rw.Write(body)
+	}))
+	defer server.Close()
+	defer close(release)
+	client := srclient.CreateSchemaRegistryClient(server.URL)
+	client.CodecCreationEnabled(false)
+	parser := NewSchemaParser(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := parser.GetProtoSchema(ctx, 1)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestValidateSchema(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		schema      string
+		expectError bool
+	}{
+		"valid": {
+			schema: moneySchema,
+		},
+		"well-known import": {
+			schema: `syntax = "proto3";
+import "google/protobuf/timestamp.proto";
+message Event { google.protobuf.Timestamp at = 1; }`,
+		},
+		"syntax error": {
+			schema:      `syntax = "proto3"; message Money {`,
+			expectError: true,
+		},
+		"unresolved import": {
+			schema:      orderSchema,
+			expectError: true,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateSchema(testData.schema)
+
+			if !testData.expectError {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, srclient.ErrInvalidSchema)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package protobuf
+
+import (
+	"fmt"
+
+	"github.com/riferrei/srclient"
+	"google.golang.org/protobuf/proto"
+)
+
+// SchemaResolver resolves the schema that serializers use to encode the
+// Protobuf messages produced to a topic. The message is given so that the
+// strategies naming subjects after the record can read its full name.
+type SchemaResolver interface {
+	ResolveSchema(topic string, msg proto.Message) (*srclient.Schema, error)
+}
+
+// SubjectNameSchemaResolver resolves the latest schema registered under the
+// subject its strategy computes from the topic and the full name of the message.
+type SubjectNameSchemaResolver struct {
+	client    srclient.ISchemaRegistryClient
+	strategy  srclient.SubjectNameStrategy
+	serdeType srclient.SerdeType
+}
+
+var _ SchemaResolver = new(SubjectNameSchemaResolver)
+
+// NewSubjectNameSchemaResolver creates a resolver that looks up the latest schema of the
+// subject the strategy computes for the topic and message, e.g. srclient.RecordNameStrategy.
+func NewSubjectNameSchemaResolver(client srclient.ISchemaRegistryClient, strategy srclient.SubjectNameStrategy,
+	serdeType srclient.SerdeType) *SubjectNameSchemaResolver {
+	return &SubjectNameSchemaResolver{
+		client:    client,
+		strategy:  strategy,
+		serdeType: serdeType,
+	}
+}
+
+// NewTopicNameSchemaResolver creates a resolver that looks up
+// the latest schema of the topic's key or value subject.
+func NewTopicNameSchemaResolver(client srclient.ISchemaRegistryClient, serdeType srclient.SerdeType) *SubjectNameSchemaResolver {
+	return NewSubjectNameSchemaResolver(client, srclient.TopicNameStrategy{}, serdeType)
+}
+
+// ResolveSchema returns the latest schema of the subject computed for the topic and message.
+// A missing subject returns an error matching srclient.ErrSubjectNotFound, even when the
+// client is created with srclient.WithNotFoundAsNil, and a schema that isn't a Protobuf
+// schema returns an error matching ErrNotProtobuf.
+func (resolver *SubjectNameSchemaResolver) ResolveSchema(topic string, msg proto.Message) (*srclient.Schema, error) {
+	subject, err := resolver.strategy.SubjectName(topic, RecordName(msg), resolver.serdeType)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := resolver.client.GetLatestSchema(subject)
+	if err != nil {
+		return nil, err
+	}
+	if schema == nil {
+		return nil, fmt.Errorf("%w: %s", srclient.ErrSubjectNotFound, subject)
+	}
+	if err := checkProtobuf(schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// RecordName returns the full name of the message, like shop.Order,
+// which is the record name Protobuf subjects are named after.
+func RecordName(msg proto.Message) string {
+	return string(msg.ProtoReflect().Descriptor().FullName())
+}
@@ -0,0 +1,84 @@
+package protobuf
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/riferrei/srclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestRecordName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "google.protobuf.Timestamp", RecordName(&timestamppb.Timestamp{}))
+}
+
+func TestSubjectNameSchemaResolver_ResolveSchema(t *testing.T) {
+	t.Parallel()
+	protobuf := srclient.Protobuf
+	avro := srclient.Avro
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var response schemaResponse
+		switch req.URL.String() {
+		case "/subjects/events-value/versions/latest":
+			response = schemaResponse{Subject: "events-value", Version: 1, Schema: moneySchema, SchemaType: &protobuf, ID: 1}
+		case "/subjects/google.protobuf.Timestamp/versions/latest":
+			response = schemaResponse{Subject: "google.protobuf.Timestamp", Version: 1, Schema: moneySchema, SchemaType: &protobuf, ID: 2}
+		case "/subjects/events-google.protobuf.Timestamp/versions/latest":
+			response = schemaResponse{Subject: "events-google.protobuf.Timestamp", Version: 1, Schema: `"string"`, SchemaType: &avro, ID: 3}
+		case "/subjects/events-key/versions/latest":
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"error_code":40401,"message":"Subject 'events-key' not found."}`))
+			return
+		default:
+			require.Fail(t, "unhandled request")
+		}
+		body, _ := json.Marshal(response)
+		rw.Write(body)
+	}))
+	defer server.Close()
+	client := srclient.CreateSchemaRegistryClient(server.URL)
+	client.CodecCreationEnabled(false)
+
+	tests := map[string]struct {
+		resolver      SchemaResolver
+		expectedID    int
+		expectedError error
+	}{
+		"topic name": {
+			resolver:   NewTopicNameSchemaResolver(client, srclient.ValueSerde),
+			expectedID: 1,
+		},
+		"record name": {
+			resolver:   NewSubjectNameSchemaResolver(client, srclient.RecordNameStrategy{}, srclient.ValueSerde),
+			expectedID: 2,
+		},
+		"not protobuf": {
+			resolver:      NewSubjectNameSchemaResolver(client, srclient.TopicRecordNameStrategy{}, srclient.ValueSerde),
+			expectedError: ErrNotProtobuf,
+		},
+		"missing subject": {
+			resolver:      NewTopicNameSchemaResolver(client, srclient.KeySerde),
+			expectedError: srclient.ErrSubjectNotFound,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			schema, err := testData.resolver.ResolveSchema("events", &timestamppb.Timestamp{})
+
+			if testData.expectedError != nil {
+				assert.ErrorIs(t, err, testData.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedID, schema.ID())
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package srclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrDiffUnsupported is returned when diffing schemas
+// that are not both Avro records.
+var ErrDiffUnsupported = errors.New("only Avro records can be diffed")
+
+// SchemaField describes a field of an Avro record. Type is the name of the type
+// of the field, or its JSON definition for complex types, and Default is the JSON
+// of its default value, which is nil when the field has no default.
+type SchemaField struct {
+	Name    string
+	Type    string
+	Default json.RawMessage
+}
+
+// FieldChange describes a field found in both versions
+// whose type or default value changed between them.
+type FieldChange struct {
+	Name string
+	Old  SchemaField
+	New  SchemaField
+}
+
+// TypeChanged reports whether the type of the field changed.
+func (change FieldChange) TypeChanged() bool {
+	return change.Old.Type != change.New.Type
+}
+
+// DefaultChanged reports whether the default value of the field changed.
+func (change FieldChange) DefaultChanged() bool {
+	return !bytes.Equal(change.Old.Default, change.New.Default)
+}
+
+// SchemaDiff lists the fields added, removed and modified between two versions
+// of the record registered under a subject. Added and modified fields are in the
+// order of the newer version, removed fields in the order of the older one.
+type SchemaDiff struct {
+	Subject     string
+	FromVersion int
+	ToVersion   int
+	Added       []SchemaField
+	Removed     []SchemaField
+	Modified    []FieldChange
+}
+
+// IsEmpty reports whether no field changed between the two versions.
+func (diff SchemaDiff) IsEmpty() bool {
+	return len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Modified) == 0
+}
+
+// diffSchemaVersions fetches both versions of the subject and diffs the fields of their records.
+func diffSchemaVersions(client ISchemaRegistryClient, subject string, fromVersion, toVersion int) (SchemaDiff, error) {
+	from, err := client.GetSchemaByVersion(subject, fromVersion)
+	if err != nil {
+		return SchemaDiff{}, err
+	}
+	to, err := client.GetSchemaByVersion(subject, toVersion)
+	if err != nil {
+		return SchemaDiff{}, err
+	}
+
+	fromFields, err := recordFields(from)
+	if err != nil {
+		return SchemaDiff{}, err
+	}
+	toFields, err := recordFields(to)
+	if err != nil {
+		return SchemaDiff{}, err
+	}
+
+	diff := SchemaDiff{Subject: subject, FromVersion: fromVersion, ToVersion: toVersion}
+	previous := make(map[string]SchemaField, len(fromFields))
+	for _, field := range fromFields {
+		previous[field.Name] = field
+	}
+	current := make(map[string]bool, len(toFields))
+	for _, field := range toFields {
+		current[field.Name] = true
+		old, ok := previous[field.Name]
+		if !ok {
+			diff.Added = append(diff.Added, field)
+			continue
+		}
+		if change := (FieldChange{Name: field.Name, Old: old, New: field}); change.TypeChanged() || change.DefaultChanged() {
+			diff.Modified = append(diff.Modified, change)
+		}
+	}
+	for _, field := range fromFields {
+		if !current[field.Name] {
+			diff.Removed = append(diff.Removed, field)
+		}
+	}
+	return diff, nil
+}
+
+// recordFields returns the fields of the Avro record defined by the schema.
+func recordFields(schema *Schema) ([]SchemaField, error) {
+	if schema.schemaType != nil && *schema.schemaType != Avro {
+		return nil, fmt.Errorf("%w: schema %d is a %s schema", ErrDiffUnsupported, schema.id, *schema.schemaType)
+	}
+
+	record, ok := avroRecord(schema.schema)
+	if !ok {
+		return nil, fmt.Errorf("%w: schema %d is not a record", ErrDiffUnsupported, schema.id)
+	}
+
+	fields := make([]SchemaField, 0)
+	for _, field := range avroFields(record) {
+		name, _ := field["name"].(string)
+		fieldType, err := avroTypeName(field["type"])
+		if err != nil {
+			return nil, err
+		}
+		schemaField := SchemaField{Name: name, Type: fieldType}
+		if defaultValue, ok := field["default"]; ok {
+			if schemaField.Default, err = json.Marshal(defaultValue); err != nil {
+				return nil, err
+			}
+		}
+		fields = append(fields, schemaField)
+	}
+	return fields, nil
+}
+
+// avroRecord parses the Avro schema, reporting
+// whether it defines a record or an error.
+func avroRecord(schema string) (map[string]interface{}, bool) {
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(schema), &record); err != nil {
+		return nil, false
+	}
+	return record, record["type"] == "record" || record["type"] == "error"
+}
+
+// avroFields returns the definitions of the fields of the parsed Avro record.
+func avroFields(record map[string]interface{}) []map[string]interface{} {
+	fields, _ := record["fields"].([]interface{})
+	result := make([]map[string]interface{}, 0, len(fields))
+	for _, field := range fields {
+		if fieldMap, ok := field.(map[string]interface{}); ok {
+			result = append(result, fieldMap)
+		}
+	}
+	return result
+}
+
+// avroTypeName returns the name of a primitive or named type, and the JSON
+// definition of other types, whose keys are sorted so they can be compared.
+func avroTypeName(fieldType interface{}) (string, error) {
+	if name, ok := fieldType.(string); ok {
+		return name, nil
+	}
+	definition, err := json.Marshal(fieldType)
+	if err != nil {
+		return "", err
+	}
+	return string(definition), nil
+}
@@ -0,0 +1,179 @@
+package srclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrReferenceCycle is returned when expanding a schema
+// whose references end up referencing themselves.
+var ErrReferenceCycle = errors.New("schema references form a cycle")
+
+// expandSchema returns the schema with the named types of its references, and theirs,
+// inlined where they are first used, so it can be parsed without Schema Registry.
+func expandSchema(client ISchemaRegistryClient, schema *Schema) (string, error) {
+	if len(schema.references) == 0 {
+		return schema.schema, nil
+	}
+	if schema.schemaType != nil && *schema.schemaType != Avro {
+		return "", fmt.Errorf("schema %d can't be expanded, only Avro schemas are supported", schema.id)
+	}
+
+	named := make(map[string]interface{})
+	if err := collectReferencedTypes(client, schema.references, named, make(map[string]bool)); err != nil {
+		return "", err
+	}
+
+	var root interface{}
+	if err := json.Unmarshal([]byte(schema.schema), &root); err != nil {
+		return "", err
+	}
+	expanded, err := json.Marshal(inlineAvroTypes(root, "", named, make(map[string]bool)))
+	if err != nil {
+		return "", err
+	}
+	return string(expanded), nil
+}
+
+// collectReferencedTypes fetches the references recursively and adds the type each defines to
+// named, keyed by its full name. visiting holds the references being fetched to detect cycles.
+func collectReferencedTypes(client ISchemaRegistryClient, references []Reference,
+	named map[string]interface{}, visiting map[string]bool) error {
+	for _, reference := range references {
+		key := fmt.Sprintf("%s/%d", reference.Subject, reference.Version)
+		if visiting[key] {
+			return fmt.Errorf("%w: %s is referenced by one of its references", ErrReferenceCycle, key)
+		}
+
+		referenced, err := client.GetSchemaByVersion(reference.Subject, reference.Version)
+		if err != nil {
+			return err
+		}
+		visiting[key] = true
+		if err := collectReferencedTypes(client, referenced.references, named, visiting); err != nil {
+			return err
+		}
+		delete(visiting, key)
+
+		var definition interface{}
+		if err := json.Unmarshal([]byte(referenced.schema), &definition); err != nil {
+			return fmt.Errorf("reference %s: %w", key, err)
+		}
+		fullName := reference.Name
+		if namedType, ok := definition.(map[string]interface{}); ok {
+			fullName, _ = avroName(namedType, "")
+		}
+		named[fullName] = definition
+	}
+	return nil
+}
+
+// inlineAvroTypes replaces the first use of every referenced type name with its
+// definition. defined holds the types already defined, which are left as names.
+func inlineAvroTypes(node interface{}, namespace string, named map[string]interface{}, defined map[string]bool) interface{} {
+	switch typed := node.(type) {
+	case string:
+		fullName := typed
+		if !strings.Contains(typed, ".") && namespace != "" {
+			fullName = namespace + "." + typed
+		}
+		for _, name := range []string{fullName, typed} {
+			definition, ok := named[name]
+			if !ok || defined[name] {
+				continue
+			}
+			if namedType, ok := definition.(map[string]interface{}); ok && namespace != "" {
+				// Keep the definition from inheriting the namespace it's inlined in
+				if _, hasNamespace := namedType["namespace"]; !hasNamespace && !strings.Contains(name, ".") {
+					namedType["namespace"] = ""
+				}
+			}
+			return inlineAvroTypes(definition, "", named, defined)
+		}
+		return typed
+	case []interface{}:
+		for i, member := range typed {
+			typed[i] = inlineAvroTypes(member, namespace, named, defined)
+		}
+		return typed
+	case map[string]interface{}:
+		switch typed["type"] {
+		case "record", "error":
+			fullName, recordNamespace := avroName(typed, namespace)
+			defined[fullName] = true
+			for _, field := range avroFields(typed) {
+				field["type"] = inlineAvroTypes(field["type"], recordNamespace, named, defined)
+			}
+		case "enum", "fixed":
+			fullName, _ := avroName(typed, namespace)
+			defined[fullName] = true
+		case "array":
+			typed["items"] = inlineAvroTypes(typed["items"], namespace, named, defined)
+		case "map":
+			typed["values"] = inlineAvroTypes(typed["values"], namespace, named, defined)
+		default:
+			typed["type"] = inlineAvroTypes(typed["type"], namespace, named, defined)
+		}
+		return typed
+	default:
+		return node
+	}
+}
+
+// avroName returns the full name and namespace of the named type,
+// which inherits the enclosing namespace unless it sets its own.
+func avroName(namedType map[string]interface{}, enclosingNamespace string) (string, string) {
+	name, _ := namedType["name"].(string)
+	if index := strings.LastIndex(name, "."); index >= 0 {
+		return name, name[:index]
+	}
+	namespace := enclosingNamespace
+	if ownNamespace, ok := namedType["namespace"].(string); ok {
+		namespace = ownNamespace
+	}
+	if namespace == "" {
+		return name, ""
+	}
+	return namespace + "." + name, namespace
+}
+
+// resolveReferences fetches the references, and theirs, keyed by their name. The references
+// of each level are fetched concurrently, and those already fetched are skipped, which keeps
+// references that end up referencing themselves from being fetched forever.
+func resolveReferences(client ISchemaRegistryClient, references []Reference) (map[string]*Schema, error) {
+	resolved := make(map[string]*Schema)
+	for len(references) > 0 {
+		pending := make([]Reference, 0, len(references))
+		for _, reference := range references {
+			if _, ok := resolved[reference.Name]; !ok {
+				resolved[reference.Name] = nil
+				pending = append(pending, reference)
+			}
+		}
+
+		schemas := make([]*Schema, len(pending))
+		errs := make([]error, len(pending))
+		var wg sync.WaitGroup
+		for i, reference := range pending {
+			wg.Add(1)
+			go func(i int, reference Reference) {
+				defer wg.Done()
+				schemas[i], errs[i] = client.GetSchemaByVersion(reference.Subject, reference.Version)
+			}(i, reference)
+		}
+		wg.Wait()
+
+		references = nil
+		for i, reference := range pending {
+			if errs[i] != nil {
+				return nil, fmt.Errorf("failed to resolve reference %s: %w", reference.Name, errs[i])
+			}
+			resolved[reference.Name] = schemas[i]
+			references = append(references, schemas[i].references...)
+		}
+	}
+	return resolved, nil
+}
@@ -2,55 +2,146 @@ package srclient
 
 import (
 	"bytes"
+	"compress/gzip"
+	"container/list"
 	"context"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"regexp"
+	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/linkedin/goavro/v2"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	"golang.org/x/sync/semaphore"
+	"golang.org/x/sync/singleflight"
 )
 
 const defaultSemaphoreWeight int64 = 16
 const defaultTimeout = 5 * time.Second
 
+// defaultMaxResponseBodySize is the size from which
+// response bodies are rejected, unless configured otherwise.
+const defaultMaxResponseBodySize int64 = 32 << 20
+
+// compressionThreshold is the size from which request
+// bodies are gzipped when compression is enabled.
+const compressionThreshold = 1024
+
+// defaultUserAgent identifies requests sent by srclient,
+// including its version when it's known from the build.
+var defaultUserAgent = func() string {
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		for _, dependency := range buildInfo.Deps {
+			if dependency.Path == "github.com/riferrei/srclient" {
+				return "srclient/" + dependency.Version
+			}
+		}
+	}
+	return "srclient"
+}()
+
 // ISchemaRegistryClient provides the
 // definition of the operations that
 // this Schema Registry client provides.
 type ISchemaRegistryClient interface {
 	GetGlobalCompatibilityLevel() (*CompatibilityLevel, error)
+	GetGlobalConfig() (*RegistryConfig, error)
+	GetSubjectConfig(subject string) (*RegistryConfig, error)
+	UpdateGlobalConfig(registryConfig RegistryConfig) (*RegistryConfig, error)
 	GetCompatibilityLevel(subject string, defaultToGlobal bool) (*CompatibilityLevel, error)
+	GetCompatibilityLevels(subjects []string, defaultToGlobal bool) (map[string]CompatibilityLevel, error)
+	GetEffectiveCompatibility(subject string) (CompatibilityLevel, bool, error)
 	GetSubjects() ([]string, error)
+	GetSubjectsWithContext(ctx context.Context) ([]string, error)
 	GetSubjectsIncludingDeleted() ([]string, error)
+	StreamSubjects(fn func(subject string) error) error
+	GetSubjectsByPrefix(prefix string, includeDeleted bool) ([]string, error)
+	GetAllSchemas(latestOnly bool, offset, limit int) ([]SchemaMetadata, error)
+	GetAllSchemasWithContext(ctx context.Context, latestOnly bool, offset, limit int) ([]SchemaMetadata, error)
+	GetMaxSchemaID() (int, error)
+	FindSubjectsWithSchema(schema string, schemaType SchemaType) (SubjectVersionResponse, error)
 	GetSchema(schemaID int) (*Schema, error)
+	GetSchemaWithContext(ctx context.Context, schemaID int) (*Schema, error)
+	GetSchemasByIDs(schemaIDs []int) (map[int]*Schema, error)
+	GetSchemaTypes() ([]SchemaType, error)
+	GetRawSchema(schemaID int) (string, error)
+	GetSchemaExpanded(schemaID int) (string, error)
+	GetSchemaFormat(schemaID int, format string) (*Schema, error)
+	DiffSchemaVersions(subject string, fromVersion, toVersion int) (SchemaDiff, error)
+	GetSchemaWithSubjects(schemaID int) (string, SubjectVersionResponse, error)
 	GetLatestSchema(subject string) (*Schema, error)
+	GetLatestSchemaWithContext(ctx context.Context, subject string) (*Schema, error)
+	GetLatestSchemaWithReferences(subject string) (*Schema, map[string]*Schema, error)
+	GetLatestSchemaMetadata(subject string) (*SchemaMetadata, error)
 	GetSchemaVersions(subject string) ([]int, error)
+	SubjectExists(subject string) (bool, error)
+	GetAllVersionsForSubject(subject string) ([]*Schema, error)
+	ReimportSubject(targetClient ISchemaRegistryClient, subject string) error
 	GetSubjectVersionsById(schemaID int) (SubjectVersionResponse, error)
 	GetSchemaByVersion(subject string, version int) (*Schema, error)
+	GetSchemaByVersionWithContext(ctx context.Context, subject string, version int) (*Schema, error)
+	GetSchemaByVersionString(subject string, version string) (*Schema, error)
+	GetSchemaByVersionIncludingDeleted(subject string, version int) (*Schema, error)
+	GetReferencedBy(subject string, version int) ([]int, error)
 	GetSchemaRegistryURL() string
+	Ping() error
+	PingWithContext(ctx context.Context) error
 	CreateSchema(subject string, schema string, schemaType SchemaType, references ...Reference) (*Schema, error)
+	CreateSchemaWithID(subject string, schema string, schemaType SchemaType, id int, version int, references ...Reference) (*Schema, error)
+	ImportSchema(subject string, schema string, schemaType SchemaType, id int, version int, references ...Reference) (*Schema, error)
+	RegisterSchemaWithUpdatedReferences(subject string, schema string, schemaType SchemaType, references []Reference) (*Schema, error)
+	CreateSchemaNormalized(subject string, schema string, schemaType SchemaType, references ...Reference) (*Schema, error)
+	CreateSchemaWithConfig(subject string, req RegisterSchemaRequest) (*Schema, error)
+	CreateSchemaFromFile(subject string, path string, references ...Reference) (*Schema, error)
+	CreateSchemaFromFileWithType(subject string, path string, schemaType SchemaType, references ...Reference) (*Schema, error)
+	CreateSchemaWithCompatibility(subject string, schema string, schemaType SchemaType, compatibility CompatibilityLevel, references ...Reference) (*Schema, error)
+	CreateSchemaAndListVersions(subject string, schema string, schemaType SchemaType, references ...Reference) (*Schema, []int, error)
+	CreateSchemaIdempotent(subject string, schema string, schemaType SchemaType, references ...Reference) (*Schema, bool, error)
 	LookupSchema(subject string, schema string, schemaType SchemaType, references ...Reference) (*Schema, error)
+	LookupSchemaNormalized(subject string, schema string, schemaType SchemaType, references ...Reference) (*Schema, error)
+	FindSchemaVersion(subject string, schema string, schemaType SchemaType, references ...Reference) (int, int, error)
+	ResolveVersionByContent(subject string, schema string, schemaType SchemaType) (int, error)
 	ChangeSubjectCompatibilityLevel(subject string, compatibility CompatibilityLevel) (*CompatibilityLevel, error)
+	DeleteSubjectCompatibilityLevel(subject string) (*CompatibilityLevel, error)
 	DeleteSubject(subject string, permanent bool) error
+	DeleteAllSubjects(permanent bool, dryRun bool) ([]string, error)
 	DeleteSubjectByVersion(subject string, version int, permanent bool) error
+	DeleteSubjectVersionLatest(subject string, permanent bool) (int, error)
+	DeleteSchemaByID(schemaID int, permanent bool) error
+	PurgeSubjectVersion(subject string, version int) error
 	SetCredentials(username string, password string)
 	SetBearerToken(token string)
 	SetTimeout(timeout time.Duration)
 	CachingEnabled(value bool)
 	ResetCache()
+	EvictSubject(subject string)
+	EvictSchemaID(schemaID int)
+	CacheStats() CacheStats
+	ResetCacheStats()
 	CodecCreationEnabled(value bool)
 	CodecJsonEnabled(value bool)
 	IsSchemaCompatible(subject, schema, version string, schemaType SchemaType, references ...Reference) (bool, error)
+	CheckSchemaCompatibility(subject, schema, version string, schemaType SchemaType, references ...Reference) (CompatibilityResult, error)
+	IsSchemaCompatibleWithAll(subject, schema string, schemaType SchemaType, references ...Reference) (bool, error)
+	CheckSchemasCompatible(subject string, candidates []SchemaCandidate) ([]CompatibilityResult, error)
+	GetGlobalMode() (Mode, error)
+	GetMode(subject string) (Mode, error)
+	UpdateMode(subject string, mode Mode, force bool) (Mode, error)
 }
 
 // SchemaRegistryClient allows interactions with
@@ -59,19 +150,51 @@ type ISchemaRegistryClient interface {
 // which in turn can be used to serialize and
 // deserialize data.
 type SchemaRegistryClient struct {
+	// The cache counters are accessed atomically and are kept
+	// first to be 64-bit aligned on 32-bit platforms.
+	idCacheHits              uint64
+	idCacheMisses            uint64
+	subjectCacheHits         uint64
+	subjectCacheMisses       uint64
 	schemaRegistryURL        string
 	credentials              *credentials
+	credentialsLock          sync.RWMutex
 	httpClient               *http.Client
 	cachingEnabled           bool
 	cachingEnabledLock       sync.RWMutex
 	codecCreationEnabled     bool
 	codecAsFullJson          bool
 	codecCreationEnabledLock sync.RWMutex
-	idSchemaCache            map[int]*Schema
+	idSchemaCache            map[int]*cachedSchema
+	idSchemaLRU              *list.List
 	idSchemaCacheLock        sync.RWMutex
-	subjectSchemaCache       map[string]*Schema
+	subjectSchemaCache       map[string]*cachedSchema
+	subjectSchemaLRU         *list.List
 	subjectSchemaCacheLock   sync.RWMutex
+	cacheTTL                 time.Duration
+	maxCacheEntries          int
 	sem                      *semaphore.Weighted
+	singleflightEnabled      bool
+	requestGroup             singleflight.Group
+	maxRetries               int
+	retryBaseDelay           time.Duration
+	retryOnPost              bool
+	createGetAttempts        int
+	createGetDelay           time.Duration
+	createGetBackoff         bool
+	logger                   Logger
+	requestObserver          func(info RequestInfo)
+	requestIDGenerator       func() string
+	tokenSource              TokenSource
+	userAgent                string
+	stripSchemaNewlines      bool
+	headers                  http.Header
+	schemaValidator          func(subject string, schema string, schemaType SchemaType) error
+	compression              bool
+	notFoundAsNil            bool
+	maxResponseBodySize      int64
+	proxyCredentials         *credentials
+	clock                    Clock
 }
 
 var _ ISchemaRegistryClient = new(SchemaRegistryClient)
@@ -110,6 +233,20 @@ func (s CompatibilityLevel) String() string {
 	return string(s)
 }
 
+// Mode defines whether the registry, or a subject,
+// accepts reads, writes or imports of schemas.
+type Mode string
+
+const (
+	ReadWrite Mode = "READWRITE"
+	ReadOnly  Mode = "READONLY"
+	Import    Mode = "IMPORT"
+)
+
+func (m Mode) String() string {
+	return string(m)
+}
+
 // Reference references use the import statement of Protobuf and
 // the $ref field of JSON Schema. They are defined by the name
 // of the import or $ref and the associated subject in the registry.
@@ -119,6 +256,52 @@ type Reference struct {
 	Version int    `json:"version"`
 }
 
+// Metadata holds the tags, properties and sensitive
+// fields attached to a schema for data contracts.
+type Metadata struct {
+	Tags       map[string][]string `json:"tags,omitempty"`
+	Properties map[string]string   `json:"properties,omitempty"`
+	Sensitive  []string            `json:"sensitive,omitempty"`
+}
+
+// Rule is a data contract rule, either migrating
+// data between schema versions or enforcing
+// constraints on the data of a domain.
+type Rule struct {
+	Name      string            `json:"name"`
+	Doc       string            `json:"doc,omitempty"`
+	Kind      string            `json:"kind,omitempty"`
+	Mode      string            `json:"mode,omitempty"`
+	Type      string            `json:"type,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+	Params    map[string]string `json:"params,omitempty"`
+	Expr      string            `json:"expr,omitempty"`
+	OnSuccess string            `json:"onSuccess,omitempty"`
+	OnFailure string            `json:"onFailure,omitempty"`
+	Disabled  bool              `json:"disabled,omitempty"`
+}
+
+// RuleSet holds the data contract rules of a schema.
+type RuleSet struct {
+	MigrationRules []Rule `json:"migrationRules,omitempty"`
+	DomainRules    []Rule `json:"domainRules,omitempty"`
+}
+
+// RegistryConfig holds the configuration of Schema Registry, or of a subject.
+// Fields Schema Registry doesn't return, as it's older or they aren't set,
+// are left to their zero value.
+type RegistryConfig struct {
+	Alias              string             `json:"alias,omitempty"`
+	Normalize          *bool              `json:"normalize,omitempty"`
+	ValidateFields     *bool              `json:"validateFields,omitempty"`
+	CompatibilityLevel CompatibilityLevel `json:"compatibilityLevel,omitempty"`
+	CompatibilityGroup string             `json:"compatibilityGroup,omitempty"`
+	DefaultMetadata    *Metadata          `json:"defaultMetadata,omitempty"`
+	OverrideMetadata   *Metadata          `json:"overrideMetadata,omitempty"`
+	DefaultRuleSet     *RuleSet           `json:"defaultRuleSet,omitempty"`
+	OverrideRuleSet    *RuleSet           `json:"overrideRuleSet,omitempty"`
+}
+
 // Schema is a data structure that holds all
 // the relevant information about schemas.
 type Schema struct {
@@ -127,8 +310,107 @@ type Schema struct {
 	schemaType *SchemaType
 	version    int
 	references []Reference
+	metadata   *Metadata
+	ruleSet    *RuleSet
 	codec      *goavro.Codec
 	jsonSchema *jsonschema.Schema
+	// lazyLock guards codec and jsonSchema,
+	// which are created on their first use
+	lazyLock sync.Mutex
+}
+
+// SchemaMetadata holds the information returned
+// about every schema when listing the registry.
+type SchemaMetadata struct {
+	Subject    string      `json:"subject"`
+	Version    int         `json:"version"`
+	ID         int         `json:"id"`
+	SchemaType SchemaType  `json:"schemaType"`
+	Schema     string      `json:"schema"`
+	References []Reference `json:"references"`
+}
+
+func newSchemaMetadata(subject string, schema *Schema) *SchemaMetadata {
+	schemaType := Avro
+	if schema.SchemaType() != nil {
+		schemaType = *schema.SchemaType()
+	}
+	return &SchemaMetadata{
+		Subject:    subject,
+		Version:    schema.Version(),
+		ID:         schema.ID(),
+		SchemaType: schemaType,
+		Schema:     schema.Schema(),
+		References: schema.References(),
+	}
+}
+
+// TokenSource provides the bearer tokens used to authenticate with
+// Schema Registry. It's called before every request, so it should
+// cache tokens until they are about to expire, like the token sources
+// from golang.org/x/oauth2 do.
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// Clock tells the time to the SchemaRegistryClient, which uses it to expire cached
+// schemas and to wait between retries, so tests can control time with WithClock.
+// The durations of requests, as logged and observed, are measured in real time.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the Clock used by default, which tells the real time.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Logger is used by the SchemaRegistryClient to
+// trace the requests sent to Schema Registry.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+}
+
+// RequestInfo describes a request sent to Schema Registry once it
+// has completed. StatusCode is zero if no response was received.
+// RequestID is the ID sent in the X-Request-ID header, if WithRequestIDGenerator is used.
+type RequestInfo struct {
+	Method     string
+	URI        string
+	RequestID  string
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+// CacheStats holds the statistics of the
+// caches kept by the SchemaRegistryClient.
+type CacheStats struct {
+	IDCache      CacheCounters
+	SubjectCache CacheCounters
+}
+
+// CacheCounters holds the number of hits and misses
+// of a cache, along with the number of schemas it holds.
+type CacheCounters struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// cachedSchema holds a cached schema along with the time it
+// was cached and its position in the least-recently-used list.
+type cachedSchema struct {
+	schema   *Schema
+	cachedAt time.Time
+	element  *list.Element
 }
 
 // credentials can have either username AND password
@@ -146,6 +428,21 @@ type schemaRequest struct {
 	Schema     string      `json:"schema"`
 	SchemaType string      `json:"schemaType,omitempty"`
 	References []Reference `json:"references,omitempty"`
+	ID         int         `json:"id,omitempty"`
+	Version    int         `json:"version,omitempty"`
+	Metadata   *Metadata   `json:"metadata,omitempty"`
+	RuleSet    *RuleSet    `json:"ruleSet,omitempty"`
+}
+
+// RegisterSchemaRequest holds a schema to register
+// along with its data contract metadata and rules,
+// which are omitted when left nil.
+type RegisterSchemaRequest struct {
+	Schema     string      `json:"schema"`
+	SchemaType SchemaType  `json:"schemaType"`
+	References []Reference `json:"references,omitempty"`
+	Metadata   *Metadata   `json:"metadata,omitempty"`
+	RuleSet    *RuleSet    `json:"ruleSet,omitempty"`
 }
 
 type schemaResponse struct {
@@ -155,10 +452,40 @@ type schemaResponse struct {
 	SchemaType *SchemaType `json:"schemaType"`
 	ID         int         `json:"id"`
 	References []Reference `json:"references"`
+	Metadata   *Metadata   `json:"metadata"`
+	RuleSet    *RuleSet    `json:"ruleSet"`
 }
 
 type isCompatibleResponse struct {
-	IsCompatible bool `json:"is_compatible"`
+	IsCompatible bool     `json:"is_compatible"`
+	Messages     []string `json:"messages"`
+}
+
+// CompatibilityResult tells whether a schema is compatible
+// and, if it isn't, the reasons given by Schema Registry.
+type CompatibilityResult struct {
+	IsCompatible bool
+	Messages     []string
+}
+
+// SchemaCandidate is a schema to check for compatibility with
+// CheckSchemasCompatible, against the given version of the subject,
+// or against all its versions if the version is empty.
+type SchemaCandidate struct {
+	Schema     string
+	SchemaType SchemaType
+	Version    string
+	References []Reference
+}
+
+// CompatibilityChecksError is returned by CheckSchemasCompatible when some of
+// the checks couldn't be done, with the error of each keyed by candidate index.
+type CompatibilityChecksError struct {
+	Errors map[int]error
+}
+
+func (e *CompatibilityChecksError) Error() string {
+	return fmt.Sprintf("failed to check %d candidates: %s", len(e.Errors), joinErrorsByInt("candidate %d: %s", e.Errors))
 }
 
 type configResponse struct {
@@ -171,6 +498,160 @@ type configChangeRequest struct {
 
 type configChangeResponse configChangeRequest
 
+// configUpdateRequest is the body of the requests updating the configuration, in which
+// Schema Registry expects the compatibility level as "compatibility". The compatibility
+// level of the embedded RegistryConfig is left empty, so it's not sent twice.
+type configUpdateRequest struct {
+	RegistryConfig
+	CompatibilityLevel CompatibilityLevel `json:"compatibility,omitempty"`
+}
+
+type modeRequest struct {
+	Mode Mode `json:"mode"`
+}
+
+type modeResponse modeRequest
+
+// RetryError is returned when a request kept failing with
+// transient errors after all the configured retries were
+// attempted. It exposes the number of attempts made and
+// the last error returned by Schema Registry.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("request failed after %d attempts: %s", e.Attempts, e.Err)
+}
+
+// Unwrap returns the last error returned by Schema Registry.
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// PurgeError is returned by PurgeSubjectVersion when purging
+// a version fails. SoftDeleted tells whether the version was
+// soft deleted before the permanent delete failed.
+type PurgeError struct {
+	SoftDeleted bool
+	Err         error
+}
+
+func (e *PurgeError) Error() string {
+	if e.SoftDeleted {
+		return fmt.Sprintf("version was soft deleted but the permanent delete failed: %s", e.Err)
+	}
+	return fmt.Sprintf("soft delete failed, version was not deleted: %s", e.Err)
+}
+
+// Unwrap returns the error returned by Schema Registry.
+func (e *PurgeError) Unwrap() error {
+	return e.Err
+}
+
+// SchemasByIDsError is returned by GetSchemasByIDs when some
+// of the schemas couldn't be fetched, with the error of each.
+type SchemasByIDsError struct {
+	Errors map[int]error
+}
+
+func (e *SchemasByIDsError) Error() string {
+	return fmt.Sprintf("failed to get %d schemas: %s", len(e.Errors), joinErrorsByInt("schema %d: %s", e.Errors))
+}
+
+// CompatibilityLevelsError is returned by GetCompatibilityLevels when the compatibility
+// level of some subjects couldn't be fetched, with the error of each.
+type CompatibilityLevelsError struct {
+	Errors map[string]error
+}
+
+func (e *CompatibilityLevelsError) Error() string {
+	return fmt.Sprintf("failed to get the compatibility level of %d subjects: %s", len(e.Errors),
+		joinErrorsByString("subject %s: %s", e.Errors))
+}
+
+// SchemaVersionsError is returned by GetAllVersionsForSubject when some
+// versions of the subject couldn't be fetched, with the error of each.
+type SchemaVersionsError struct {
+	Subject string
+	Errors  map[int]error
+}
+
+func (e *SchemaVersionsError) Error() string {
+	return fmt.Sprintf("failed to get %d versions of subject %s: %s", len(e.Errors), e.Subject,
+		joinErrorsByInt("version %d: %s", e.Errors))
+}
+
+// DanglingReferencesError is returned by RegisterSchemaWithUpdatedReferences
+// when references point to subject versions that don't exist.
+type DanglingReferencesError struct {
+	References []Reference
+}
+
+func (e *DanglingReferencesError) Error() string {
+	references := make([]string, 0, len(e.References))
+	for _, reference := range e.References {
+		references = append(references, fmt.Sprintf("%s (subject %s, version %d)", reference.Name, reference.Subject, reference.Version))
+	}
+	return fmt.Sprintf("references to missing schemas: %s", strings.Join(references, ", "))
+}
+
+// DeleteSubjectsError is returned by DeleteAllSubjects when some
+// of the subjects couldn't be deleted, with the error of each.
+type DeleteSubjectsError struct {
+	Errors map[string]error
+}
+
+func (e *DeleteSubjectsError) Error() string {
+	return fmt.Sprintf("failed to delete %d subjects: %s", len(e.Errors), joinErrorsByString("subject %s: %s", e.Errors))
+}
+
+// DeleteSchemaError is returned by DeleteSchemaByID when some of the
+// subject versions of the schema couldn't be deleted, with the error
+// of each keyed by the subject and version, like "subject/1".
+type DeleteSchemaError struct {
+	SchemaID int
+	Errors   map[string]error
+}
+
+func (e *DeleteSchemaError) Error() string {
+	return fmt.Sprintf("failed to delete %d versions of schema %d: %s", len(e.Errors), e.SchemaID,
+		joinErrorsByString("%s: %s", e.Errors))
+}
+
+// joinErrorsByInt formats the errors of the aggregate error types keyed by
+// an int, like a schema ID, in the order of their keys, separated by ";".
+func joinErrorsByInt(format string, errs map[int]error) string {
+	keys := make([]int, 0, len(errs))
+	for key := range errs {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	messages := make([]string, 0, len(keys))
+	for _, key := range keys {
+		messages = append(messages, fmt.Sprintf(format, key, errs[key]))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// joinErrorsByString works like joinErrorsByInt
+// for the errors keyed by a string, like a subject.
+func joinErrorsByString(format string, errs map[string]error) string {
+	keys := make([]string, 0, len(errs))
+	for key := range errs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	messages := make([]string, 0, len(keys))
+	for _, key := range keys {
+		messages = append(messages, fmt.Sprintf(format, key, errs[key]))
+	}
+	return strings.Join(messages, "; ")
+}
+
 type SubjectVersionResponse []subjectVersionPair
 
 type subjectVersionPair struct {
@@ -180,20 +661,50 @@ type subjectVersionPair struct {
 
 const (
 	schemaByID          = "/schemas/ids/%d"
+	rawSchemaByID       = "/schemas/ids/%d/schema"
 	subjectVersionsByID = "/schemas/ids/%d/versions"
 	subjectBySubject    = "/subjects/%s"
 	subjectVersions     = "/subjects/%s/versions"
 	subjectByVersion    = "/subjects/%s/versions/%s"
+	referencedBy        = "/subjects/%s/versions/%d/referencedby"
 	subjects            = "/subjects"
+	schemas             = "/schemas"
+	schemaTypes         = "/schemas/types"
 	config              = "/config"
 	configBySubject     = "/config/%s"
+	mode                = "/mode"
+	modeBySubject       = "/mode/%s"
 	contentType         = "application/vnd.schemaregistry.v1+json"
+	requestIDHeader     = "X-Request-ID"
 )
 
 // schemaRegistryConfig is used in NewSchemaRegistryClient and is configured through Option
 type schemaRegistryConfig struct {
-	client          *http.Client
-	semaphoreWeight int64
+	client              *http.Client
+	semaphoreWeight     int64
+	logger              Logger
+	requestObserver     func(info RequestInfo)
+	requestIDGenerator  func() string
+	tokenSource         TokenSource
+	userAgent           string
+	keepSchemaNewlines  bool
+	headers             http.Header
+	disableSingleflight bool
+	cacheTTL            time.Duration
+	maxCacheEntries     int
+	maxRetries          int
+	retryBaseDelay      time.Duration
+	retryOnPost         bool
+	createGetAttempts   int
+	createGetDelay      time.Duration
+	createGetBackoff    bool
+	schemaValidator     func(subject string, schema string, schemaType SchemaType) error
+	compression         bool
+	notFoundAsNil       bool
+	maxResponseBodySize int64
+	credentials         *credentials
+	proxyCredentials    *credentials
+	clock               Clock
 }
 
 // Option serves as an input for NewSchemaRegistryClient
@@ -206,6 +717,47 @@ func WithClient(client *http.Client) Option {
 	}
 }
 
+// WithTLSConfig is used in NewSchemaRegistryClient to set the TLS configuration of the
+// default client, e.g. to authenticate with a client certificate, while keeping its
+// timeout. It's mutually exclusive with WithClient, the last option given wins.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		registryConfig.client = &http.Client{
+			Timeout:   registryConfig.client.Timeout,
+			Transport: transport,
+		}
+	}
+}
+
+// WithInsecureSkipVerify is used in NewSchemaRegistryClient to skip the verification of
+// the certificate of Schema Registry, e.g. when it's self-signed in development, while
+// keeping the rest of the client, like its timeout and TLS configuration. Clients whose
+// transport isn't an *http.Transport are left unchanged, as there is no TLS configuration
+// to change. This makes connections open to man-in-the-middle attacks, and must never be
+// used in production.
+func WithInsecureSkipVerify() Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		var transport *http.Transport
+		switch roundTripper := registryConfig.client.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = roundTripper.Clone()
+		default:
+			return
+		}
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.InsecureSkipVerify = true
+		client := *registryConfig.client
+		client.Transport = transport
+		registryConfig.client = &client
+	}
+}
+
 // WithSemaphoreWeight is used in NewSchemaRegistryClient to override the default semaphoreWeight
 func WithSemaphoreWeight(semaphoreWeight int64) Option {
 	return func(registryConfig *schemaRegistryConfig) {
@@ -213,14 +765,221 @@ func WithSemaphoreWeight(semaphoreWeight int64) Option {
 	}
 }
 
+// WithLogger is used in NewSchemaRegistryClient to log the method, URI, status code
+// and duration of every request sent to Schema Registry. Nothing is logged by default.
+func WithLogger(logger Logger) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.logger = logger
+	}
+}
+
+// WithRequestObserver is used in NewSchemaRegistryClient to be notified every time a
+// request to Schema Registry completes, successfully or not, which allows recording
+// spans and metrics. Retried requests are reported once, with their total duration.
+func WithRequestObserver(observer func(info RequestInfo)) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.requestObserver = observer
+	}
+}
+
+// WithRequestIDGenerator is used in NewSchemaRegistryClient to send an X-Request-ID header
+// with every call to Schema Registry, whose value is returned by the generator. The same ID
+// is sent when the call is retried, and it's passed to the request observer as well as
+// logged along with the other headers. No request ID is sent by default.
+func WithRequestIDGenerator(generator func() string) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.requestIDGenerator = generator
+	}
+}
+
+// WithoutSingleflight is used in NewSchemaRegistryClient to stop concurrent calls
+// fetching the same schema from sharing a single in-flight request, which they do by default.
+func WithoutSingleflight() Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.disableSingleflight = true
+	}
+}
+
+// WithCacheTTL is used in NewSchemaRegistryClient to expire cached schemas once they
+// are older than the given TTL, so they are fetched again from Schema Registry. This
+// allows picking up the latest version of subjects. Cached schemas never expire by default.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.cacheTTL = ttl
+	}
+}
+
+// WithMaxCacheEntries is used in NewSchemaRegistryClient to bound the number of schemas
+// held by each cache. Once a cache is full, the least recently used schema is evicted.
+// A value of zero, the default, leaves the caches unbounded.
+func WithMaxCacheEntries(maxEntries int) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.maxCacheEntries = maxEntries
+	}
+}
+
+// WithHeader is used in NewSchemaRegistryClient to send a static header with every
+// request, and can be given multiple times. Headers set by the client, like
+// Content-Type or Authorization, are only replaced if given explicitly.
+func WithHeader(key, value string) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.headers.Add(key, value)
+	}
+}
+
+// WithUserAgent is used in NewSchemaRegistryClient to set the User-Agent header
+// of every request, which defaults to srclient followed by its version.
+func WithUserAgent(userAgent string) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.userAgent = userAgent
+	}
+}
+
+// WithoutSchemaNewlineStripping is used in NewSchemaRegistryClient to send Avro and Json
+// schemas with their newlines, which are otherwise replaced by spaces when creating and
+// looking up schemas. The stripping is kept by default because legacy Schema Registry
+// versions reject literal newlines in the request body, but it mangles newlines in
+// string defaults and docs, which current versions accept as they are JSON-escaped.
+func WithoutSchemaNewlineStripping() Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.keepSchemaNewlines = true
+	}
+}
+
+// WithTokenSource is used in NewSchemaRegistryClient to authenticate with bearer tokens
+// fetched from the token source before every request, so short-lived tokens are refreshed.
+// It takes precedence over the credentials set through SetCredentials and SetBearerToken.
+func WithTokenSource(tokenSource TokenSource) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.tokenSource = tokenSource
+	}
+}
+
+// WithBearerToken is used in NewSchemaRegistryClient to authenticate with the bearer
+// token, like SetBearerToken does. It can be combined with WithProxyBasicAuth.
+func WithBearerToken(token string) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		if len(token) > 0 {
+			registryConfig.credentials = &credentials{bearerToken: token}
+		}
+	}
+}
+
+// WithProxyBasicAuth is used in NewSchemaRegistryClient to send the username and password
+// in the Proxy-Authorization header of every request, for gateways in front of Schema
+// Registry that require their own credentials. They are sent independently of the
+// Authorization header, so they can be combined with WithBearerToken or SetCredentials.
+// Forward proxies configured in the transport of the HTTP client take their credentials
+// from the proxy URL instead.
+func WithProxyBasicAuth(username, password string) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.proxyCredentials = &credentials{username: username, password: password}
+	}
+}
+
+// WithClock is used in NewSchemaRegistryClient to replace the clock used to expire
+// cached schemas and to wait between retries, which allows tests to advance time
+// instead of sleeping. The real time is used by default.
+func WithClock(clock Clock) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.clock = clock
+	}
+}
+
+// WithRetry is used in NewSchemaRegistryClient to retry GET requests that fail
+// with a 5xx or 429 response or a network error. Retries are delayed using exponential
+// backoff with jitter, starting from baseDelay, unless the response has a Retry-After
+// header, which is honored instead. Requests are attempted only once by default.
+func WithRetry(maxRetries int, baseDelay time.Duration) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.maxRetries = maxRetries
+		registryConfig.retryBaseDelay = baseDelay
+	}
+}
+
+// WithRetryOnPost is used in NewSchemaRegistryClient to also retry POST requests
+// when WithRetry is set. POST requests are not retried by default as they are not idempotent.
+func WithRetryOnPost(enabled bool) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.retryOnPost = enabled
+	}
+}
+
+// WithCreateGetRetry is used in NewSchemaRegistryClient to retry the fetch CreateSchema
+// does right after registering a schema, whenever it fails with 40403 (schema not found).
+// This happens when the registry has multiple backends and the fetch hits one that
+// hasn't caught up with the registration yet. Only a single attempt is made by default.
+func WithCreateGetRetry(attempts int, delay time.Duration) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.createGetAttempts = attempts
+		registryConfig.createGetDelay = delay
+		registryConfig.createGetBackoff = false
+	}
+}
+
+// WithStaleReadRetry is used in NewSchemaRegistryClient to retry the reads done right
+// after a write, like WithCreateGetRetry, but doubling the delay after every attempt.
+// Only responses with the 40403 error code are retried, and the last one is returned
+// once the attempts are exhausted, so schemas that don't exist are still reported.
+func WithStaleReadRetry(attempts int, delay time.Duration) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.createGetAttempts = attempts
+		registryConfig.createGetDelay = delay
+		registryConfig.createGetBackoff = true
+	}
+}
+
+// WithSchemaValidator is used in NewSchemaRegistryClient to check every schema before it's
+// created or looked up, e.g. to enforce naming conventions. The validator sees the schema as
+// it will be sent, after newline stripping, and a non-nil error aborts the call with it
+// before any request is made.
+func WithSchemaValidator(validator func(subject string, schema string, schemaType SchemaType) error) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.schemaValidator = validator
+	}
+}
+
+// WithCompression is used in NewSchemaRegistryClient to ask Schema Registry for gzipped
+// responses, which are decompressed transparently, and to gzip request bodies of 1KB or
+// more. This saves bandwidth when transferring large schemas.
+func WithCompression() Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.compression = true
+	}
+}
+
+// WithNotFoundAsNil is used in NewSchemaRegistryClient to make GetLatestSchema return
+// a nil schema and no error when the subject doesn't exist, so checking whether the
+// schema is nil tells if it has to be created. Other errors are returned as usual.
+func WithNotFoundAsNil() Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.notFoundAsNil = true
+	}
+}
+
+// WithMaxResponseBodySize is used in NewSchemaRegistryClient to bound the size of the
+// responses read from Schema Registry, so a misbehaving endpoint can't exhaust the memory
+// of the process. Larger responses fail with ErrResponseTooLarge, and the bodies of error
+// responses are truncated. It defaults to 32MB, and a size of zero or less removes the limit.
+// Responses streamed by StreamSubjects are not bounded, as they are not held in memory.
+func WithMaxResponseBodySize(size int64) Option {
+	return func(registryConfig *schemaRegistryConfig) {
+		registryConfig.maxResponseBodySize = size
+	}
+}
+
 // NewSchemaRegistryClient creates a client that allows
 // interactions with Schema Registry over HTTP. Applications
 // using this client can retrieve data about schemas, which
 // in turn can be used to serialize and deserialize records.
 func NewSchemaRegistryClient(schemaRegistryURL string, options ...Option) *SchemaRegistryClient {
 	config := &schemaRegistryConfig{
-		client:          &http.Client{Timeout: defaultTimeout},
-		semaphoreWeight: defaultSemaphoreWeight,
+		client:              &http.Client{Timeout: defaultTimeout},
+		semaphoreWeight:     defaultSemaphoreWeight,
+		userAgent:           defaultUserAgent,
+		headers:             make(http.Header),
+		maxResponseBodySize: defaultMaxResponseBodySize,
+		clock:               realClock{},
 	}
 
 	for _, option := range options {
@@ -232,9 +991,34 @@ func NewSchemaRegistryClient(schemaRegistryURL string, options ...Option) *Schem
 		httpClient:           config.client,
 		cachingEnabled:       true,
 		codecCreationEnabled: false,
-		idSchemaCache:        make(map[int]*Schema),
-		subjectSchemaCache:   make(map[string]*Schema),
+		idSchemaCache:        make(map[int]*cachedSchema),
+		idSchemaLRU:          list.New(),
+		subjectSchemaCache:   make(map[string]*cachedSchema),
+		subjectSchemaLRU:     list.New(),
+		cacheTTL:             config.cacheTTL,
+		maxCacheEntries:      config.maxCacheEntries,
 		sem:                  semaphore.NewWeighted(config.semaphoreWeight),
+		singleflightEnabled:  !config.disableSingleflight,
+		maxRetries:           config.maxRetries,
+		retryBaseDelay:       config.retryBaseDelay,
+		retryOnPost:          config.retryOnPost,
+		createGetAttempts:    config.createGetAttempts,
+		createGetDelay:       config.createGetDelay,
+		createGetBackoff:     config.createGetBackoff,
+		logger:               config.logger,
+		requestObserver:      config.requestObserver,
+		requestIDGenerator:   config.requestIDGenerator,
+		tokenSource:          config.tokenSource,
+		userAgent:            config.userAgent,
+		stripSchemaNewlines:  !config.keepSchemaNewlines,
+		headers:              config.headers,
+		schemaValidator:      config.schemaValidator,
+		compression:          config.compression,
+		notFoundAsNil:        config.notFoundAsNil,
+		maxResponseBodySize:  config.maxResponseBodySize,
+		credentials:          config.credentials,
+		proxyCredentials:     config.proxyCredentials,
+		clock:                config.clock,
 	}
 }
 
@@ -258,29 +1042,177 @@ func (client *SchemaRegistryClient) GetSchemaRegistryURL() string {
 	return client.schemaRegistryURL
 }
 
-// ResetCache resets the schema caches to be able to get updated schemas.
+// Ping checks that Schema Registry is reachable and answering with a 2xx
+// response, without side effects. It's meant for readiness probes and
+// startup checks.
+func (client *SchemaRegistryClient) Ping() error {
+	return client.PingWithContext(context.Background())
+}
+
+// PingWithContext is Ping bound to the context. If the context has a
+// deadline, it replaces the client timeout for this call, see SetTimeout.
+func (client *SchemaRegistryClient) PingWithContext(ctx context.Context) error {
+	if _, err := client.httpRequestWithContext(ctx, "GET", "/", nil); err != nil {
+		return fmt.Errorf("failed to reach Schema Registry at %s: %w", client.schemaRegistryURL, err)
+	}
+	return nil
+}
+
+// ResetCache resets the schema caches to be able to get updated schemas,
+// along with their statistics.
 func (client *SchemaRegistryClient) ResetCache() {
 	client.idSchemaCacheLock.Lock()
 	client.subjectSchemaCacheLock.Lock()
-	client.idSchemaCache = make(map[int]*Schema)
-	client.subjectSchemaCache = make(map[string]*Schema)
+	client.idSchemaCache = make(map[int]*cachedSchema)
+	client.idSchemaLRU.Init()
+	client.subjectSchemaCache = make(map[string]*cachedSchema)
+	client.subjectSchemaLRU.Init()
 	client.idSchemaCacheLock.Unlock()
 	client.subjectSchemaCacheLock.Unlock()
+	client.ResetCacheStats()
+}
+
+// EvictSubject removes every cached version of the subject, including
+// its latest one, leaving the rest of the caches untouched.
+func (client *SchemaRegistryClient) EvictSubject(subject string) {
+	client.subjectSchemaCacheLock.Lock()
+	defer client.subjectSchemaCacheLock.Unlock()
+	prefix := cacheKey(subject, "")
+	for key, cached := range client.subjectSchemaCache {
+		if !strings.HasPrefix(key, prefix) || !isCachedVersion(strings.TrimPrefix(key, prefix)) {
+			continue
+		}
+		client.subjectSchemaLRU.Remove(cached.element)
+		delete(client.subjectSchemaCache, key)
+	}
+}
+
+// EvictSchemaID removes the schema with the given ID from the
+// cache, leaving the rest of the caches untouched.
+func (client *SchemaRegistryClient) EvictSchemaID(schemaID int) {
+	client.idSchemaCacheLock.Lock()
+	defer client.idSchemaCacheLock.Unlock()
+	if cached, ok := client.idSchemaCache[schemaID]; ok {
+		client.idSchemaLRU.Remove(cached.element)
+		delete(client.idSchemaCache, schemaID)
+	}
+}
+
+// isCachedVersion reports whether version is one the subject cache is keyed by,
+// so subjects prefixed by another subject's name are not evicted along with it.
+func isCachedVersion(version string) bool {
+	if version == "latest" {
+		return true
+	}
+	_, err := strconv.Atoi(version)
+	return err == nil
+}
+
+// evictDeleted removes the deleted version of the subject, or all its versions if version
+// is zero, from the subject cache along with their schemas from the ID cache. The latest
+// version is always removed, as it may have been deleted or now resolve to another one.
+func (client *SchemaRegistryClient) evictDeleted(subject string, version int) {
+	var schemaIDs []int
+	client.subjectSchemaCacheLock.Lock()
+	prefix := cacheKey(subject, "")
+	for key, cached := range client.subjectSchemaCache {
+		cachedVersion := strings.TrimPrefix(key, prefix)
+		if !strings.HasPrefix(key, prefix) || !isCachedVersion(cachedVersion) {
+			continue
+		}
+		deleted := version == 0 || cached.schema.version == version || cachedVersion == strconv.Itoa(version)
+		if !deleted && cachedVersion != "latest" {
+			continue
+		}
+		if deleted {
+			schemaIDs = append(schemaIDs, cached.schema.id)
+		}
+		client.subjectSchemaLRU.Remove(cached.element)
+		delete(client.subjectSchemaCache, key)
+	}
+	client.subjectSchemaCacheLock.Unlock()
+
+	for _, schemaID := range schemaIDs {
+		client.EvictSchemaID(schemaID)
+	}
+}
+
+// CacheStats returns the hits, misses and number of
+// entries of the ID and subject caches.
+func (client *SchemaRegistryClient) CacheStats() CacheStats {
+	client.idSchemaCacheLock.RLock()
+	idEntries := len(client.idSchemaCache)
+	client.idSchemaCacheLock.RUnlock()
+	client.subjectSchemaCacheLock.RLock()
+	subjectEntries := len(client.subjectSchemaCache)
+	client.subjectSchemaCacheLock.RUnlock()
+
+	return CacheStats{
+		IDCache: CacheCounters{
+			Hits:    atomic.LoadUint64(&client.idCacheHits),
+			Misses:  atomic.LoadUint64(&client.idCacheMisses),
+			Entries: idEntries,
+		},
+		SubjectCache: CacheCounters{
+			Hits:    atomic.LoadUint64(&client.subjectCacheHits),
+			Misses:  atomic.LoadUint64(&client.subjectCacheMisses),
+			Entries: subjectEntries,
+		},
+	}
+}
+
+// ResetCacheStats resets the hits and misses
+// counters without touching the caches.
+func (client *SchemaRegistryClient) ResetCacheStats() {
+	atomic.StoreUint64(&client.idCacheHits, 0)
+	atomic.StoreUint64(&client.idCacheMisses, 0)
+	atomic.StoreUint64(&client.subjectCacheHits, 0)
+	atomic.StoreUint64(&client.subjectCacheMisses, 0)
 }
 
 // GetSchema gets the schema associated with the given id.
 func (client *SchemaRegistryClient) GetSchema(schemaID int) (*Schema, error) {
+	return client.GetSchemaWithContext(context.Background(), schemaID)
+}
 
+// GetSchemaWithContext is GetSchema bound to the context. The call returns as soon
+// as the context is done. Concurrent calls for the same id share a single request,
+// bound by the client timeout, see SetTimeout, unless singleflight is disabled, in
+// which case the deadline of the context replaces the client timeout.
+func (client *SchemaRegistryClient) GetSchemaWithContext(ctx context.Context, schemaID int) (*Schema, error) {
 	if client.getCachingEnabled() {
-		client.idSchemaCacheLock.RLock()
-		cachedSchema := client.idSchemaCache[schemaID]
-		client.idSchemaCacheLock.RUnlock()
-		if cachedSchema != nil {
+		if cachedSchema := client.getCachedSchemaByID(schemaID); cachedSchema != nil {
+			atomic.AddUint64(&client.idCacheHits, 1)
 			return cachedSchema, nil
 		}
+		atomic.AddUint64(&client.idCacheMisses, 1)
+	}
+
+	return client.dedupe(ctx, fmt.Sprintf("id-%d", schemaID), func(ctx context.Context) (*Schema, error) {
+		return client.fetchSchema(ctx, schemaID, "")
+	})
+}
+
+// GetSchemaFormat gets the schema associated with the given id, rendered in the given
+// format. The formats depend on the schema type: "resolved" inlines the references of
+// Avro schemas, while "serialized" returns Protobuf schemas as base64-encoded file
+// descriptors and "ignore_extensions" leaves their extensions out. An empty format
+// works like GetSchema. Schemas rendered in a format are not cached.
+func (client *SchemaRegistryClient) GetSchemaFormat(schemaID int, format string) (*Schema, error) {
+	if format == "" {
+		return client.GetSchema(schemaID)
 	}
+	return client.fetchSchema(context.Background(), schemaID, format)
+}
 
-	resp, err := client.httpRequest("GET", fmt.Sprintf(schemaByID, schemaID), nil)
+// fetchSchema gets the schema associated with the given id, in the given format if any.
+// Only schemas in the default format are cached.
+func (client *SchemaRegistryClient) fetchSchema(ctx context.Context, schemaID int, format string) (*Schema, error) {
+	uri := fmt.Sprintf(schemaByID, schemaID)
+	if format != "" {
+		uri += "?format=" + url.QueryEscape(format)
+	}
+	resp, err := client.httpRequestWithContext(ctx, "GET", uri, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -304,25 +1236,115 @@ func (client *SchemaRegistryClient) GetSchema(schemaID int) (*Schema, error) {
 		version:    schemaResp.Version,
 		schemaType: schemaResp.SchemaType,
 		references: schemaResp.References,
+		metadata:   schemaResp.Metadata,
+		ruleSet:    schemaResp.RuleSet,
 		codec:      codec,
 	}
 
-	if client.getCachingEnabled() {
-		client.idSchemaCacheLock.Lock()
-		client.idSchemaCache[schemaID] = schema
-		client.idSchemaCacheLock.Unlock()
+	if client.getCachingEnabled() && format == "" {
+		client.cacheSchemaByID(schemaID, schema)
 	}
 
 	return schema, nil
 }
 
-// GetLatestSchema gets the schema associated with the given subject.
-// The schema returned contains the last version for that subject.
-func (client *SchemaRegistryClient) GetLatestSchema(subject string) (*Schema, error) {
-	return client.getVersion(subject, "latest")
-}
-
-// GetSubjectVersionsById returns subject-version pairs identified by the schema ID.
+// GetRawSchema gets only the schema string for the given ID, without
+// creating its codec. Cached schemas are returned from the cache, but
+// as the response lacks the schema type and references, the schema
+// string isn't cached by itself, nor counted as a cache miss.
+func (client *SchemaRegistryClient) GetRawSchema(schemaID int) (string, error) {
+	if client.getCachingEnabled() {
+		if cachedSchema := client.getCachedSchemaByID(schemaID); cachedSchema != nil {
+			atomic.AddUint64(&client.idCacheHits, 1)
+			return cachedSchema.Schema(), nil
+		}
+	}
+
+	resp, err := client.httpRequest("GET", fmt.Sprintf(rawSchemaByID, schemaID), nil)
+	if err != nil {
+		return "", err
+	}
+
+	// The schema is returned as is, not wrapped in a JSON object
+	return string(resp), nil
+}
+
+// GetSchemaWithSubjects gets the schema string for the given ID along with
+// every subject-version pair it's registered under, as the version of the
+// schema returned by GetSchema is ambiguous when the ID is shared by subjects.
+func (client *SchemaRegistryClient) GetSchemaWithSubjects(schemaID int) (string, SubjectVersionResponse, error) {
+	schema, err := client.GetRawSchema(schemaID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	subjectVersions, err := client.GetSubjectVersionsById(schemaID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return schema, subjectVersions, nil
+}
+
+// GetLatestSchema gets the schema associated with the given subject.
+// The schema returned contains the last version for that subject.
+// If the subject doesn't exist, the error matches ErrSubjectNotFound,
+// unless the client is created with WithNotFoundAsNil.
+func (client *SchemaRegistryClient) GetLatestSchema(subject string) (*Schema, error) {
+	return client.GetLatestSchemaWithContext(context.Background(), subject)
+}
+
+// GetLatestSchemaWithContext is GetLatestSchema bound to the context. The call returns
+// as soon as the context is done, like GetSchemaWithContext, and concurrent calls for
+// the same subject share a single request, bound by the client timeout.
+func (client *SchemaRegistryClient) GetLatestSchemaWithContext(ctx context.Context, subject string) (*Schema, error) {
+	schema, err := client.getVersion(ctx, subject, "latest")
+	if client.notFoundAsNil && errors.Is(err, ErrSubjectNotFound) {
+		return nil, nil
+	}
+	return schema, err
+}
+
+// GetLatestSchemaWithReferences gets the latest schema of the subject like GetLatestSchema,
+// along with the schemas it references, and those they reference, keyed by reference name.
+// The references are fetched concurrently, as many at once as the semaphore weight allows.
+func (client *SchemaRegistryClient) GetLatestSchemaWithReferences(subject string) (*Schema, map[string]*Schema, error) {
+	schema, err := client.GetLatestSchema(subject)
+	if err != nil || schema == nil {
+		return nil, nil, err
+	}
+	references, err := resolveReferences(client, schema.references)
+	if err != nil {
+		return nil, nil, err
+	}
+	return schema, references, nil
+}
+
+// GetLatestSchemaMetadata gets the ID, version, type and references of the latest
+// schema of the subject. Unlike GetLatestSchema, it never creates a codec, even if
+// codec creation is enabled, which makes it suitable for non-Avro schemas. Cached
+// schemas are returned from the cache, but when codec creation is enabled, the
+// fetched schema isn't cached, as it lacks its codec.
+func (client *SchemaRegistryClient) GetLatestSchemaMetadata(subject string) (*SchemaMetadata, error) {
+	cacheKey := cacheKey(subject, "latest")
+	if client.getCachingEnabled() {
+		if cachedResult := client.getCachedSchemaBySubject(cacheKey); cachedResult != nil {
+			atomic.AddUint64(&client.subjectCacheHits, 1)
+			return newSchemaMetadata(subject, cachedResult), nil
+		}
+		atomic.AddUint64(&client.subjectCacheMisses, 1)
+	}
+
+	schema, err := client.dedupe(context.Background(), "metadata-"+cacheKey, func(ctx context.Context) (*Schema, error) {
+		return client.fetchVersion(ctx, subject, "latest", false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newSchemaMetadata(subject, schema), nil
+}
+
+// GetSubjectVersionsById returns subject-version pairs identified by the schema ID.
 func (client *SchemaRegistryClient) GetSubjectVersionsById(schemaID int) (SubjectVersionResponse, error) {
 	resp, err := client.httpRequest("GET", fmt.Sprintf(subjectVersionsByID, schemaID), nil)
 	if err != nil {
@@ -340,7 +1362,7 @@ func (client *SchemaRegistryClient) GetSubjectVersionsById(schemaID int) (Subjec
 
 // GetSchemaVersions returns a list of versions from a given subject.
 func (client *SchemaRegistryClient) GetSchemaVersions(subject string) ([]int, error) {
-	resp, err := client.httpRequest("GET", fmt.Sprintf(subjectVersions, url.QueryEscape(subject)), nil)
+	resp, err := client.httpRequest("GET", fmt.Sprintf(subjectVersions, url.PathEscape(subject)), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -354,6 +1376,17 @@ func (client *SchemaRegistryClient) GetSchemaVersions(subject string) ([]int, er
 	return versions, nil
 }
 
+// SubjectExists reports whether the subject has any version registered, which
+// is lighter than fetching one of its schemas. Errors other than the subject
+// not being found are returned as they are.
+func (client *SchemaRegistryClient) SubjectExists(subject string) (bool, error) {
+	_, err := client.httpRequest("GET", fmt.Sprintf(subjectVersions, url.PathEscape(subject)), nil)
+	if errors.Is(err, ErrSubjectNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 // ChangeSubjectCompatibilityLevel changes the compatibility level of the subject.
 func (client *SchemaRegistryClient) ChangeSubjectCompatibilityLevel(subject string, compatibility CompatibilityLevel) (*CompatibilityLevel, error) {
 	configChangeReq := configChangeRequest{CompatibilityLevel: compatibility}
@@ -363,7 +1396,7 @@ func (client *SchemaRegistryClient) ChangeSubjectCompatibilityLevel(subject stri
 	}
 	payload := bytes.NewBuffer(configChangeReqBytes)
 
-	resp, err := client.httpRequest("PUT", fmt.Sprintf(configBySubject, url.QueryEscape(subject)), payload)
+	resp, err := client.httpRequest("PUT", fmt.Sprintf(configBySubject, url.PathEscape(subject)), payload)
 	if err != nil {
 		return nil, err
 	}
@@ -377,6 +1410,22 @@ func (client *SchemaRegistryClient) ChangeSubjectCompatibilityLevel(subject stri
 	return &cfgChangeResp.CompatibilityLevel, nil
 }
 
+// DeleteSubjectCompatibilityLevel deletes the compatibility level of the subject,
+// which reverts to the global compatibility level.
+func (client *SchemaRegistryClient) DeleteSubjectCompatibilityLevel(subject string) (*CompatibilityLevel, error) {
+	resp, err := client.httpRequest("DELETE", fmt.Sprintf(configBySubject, url.PathEscape(subject)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var configResponse = new(configResponse)
+	if err := json.Unmarshal(resp, &configResponse); err != nil {
+		return nil, err
+	}
+
+	return &configResponse.CompatibilityLevel, nil
+}
+
 // GetGlobalCompatibilityLevel returns the global compatibility level of the registry.
 func (client *SchemaRegistryClient) GetGlobalCompatibilityLevel() (*CompatibilityLevel, error) {
 	resp, err := client.httpRequest("GET", config, nil)
@@ -393,10 +1442,60 @@ func (client *SchemaRegistryClient) GetGlobalCompatibilityLevel() (*Compatibilit
 	return &configResponse.CompatibilityLevel, nil
 }
 
+// GetGlobalConfig returns the global configuration of Schema Registry,
+// which includes the global compatibility level along with the other settings.
+func (client *SchemaRegistryClient) GetGlobalConfig() (*RegistryConfig, error) {
+	return client.getConfig(config)
+}
+
+// GetSubjectConfig returns the configuration set on the subject, failing with
+// ErrCompatibilityNotConfigured if the subject has no configuration of its own.
+func (client *SchemaRegistryClient) GetSubjectConfig(subject string) (*RegistryConfig, error) {
+	return client.getConfig(fmt.Sprintf(configBySubject, url.PathEscape(subject)))
+}
+
+// UpdateGlobalConfig updates the global configuration of Schema Registry in a single
+// request, e.g. to enable normalization along with setting the compatibility level.
+// Only the fields that are set are sent, so the others keep their current value.
+func (client *SchemaRegistryClient) UpdateGlobalConfig(registryConfig RegistryConfig) (*RegistryConfig, error) {
+	configUpdateReq := configUpdateRequest{RegistryConfig: registryConfig, CompatibilityLevel: registryConfig.CompatibilityLevel}
+	configUpdateReq.RegistryConfig.CompatibilityLevel = ""
+	configUpdateReqBytes, err := json.Marshal(configUpdateReq)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.httpRequest("PUT", config, bytes.NewBuffer(configUpdateReqBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	var configUpdateResp configUpdateRequest
+	if err := json.Unmarshal(resp, &configUpdateResp); err != nil {
+		return nil, err
+	}
+	updatedConfig := configUpdateResp.RegistryConfig
+	updatedConfig.CompatibilityLevel = configUpdateResp.CompatibilityLevel
+	return &updatedConfig, nil
+}
+
+func (client *SchemaRegistryClient) getConfig(uri string) (*RegistryConfig, error) {
+	resp, err := client.httpRequest("GET", uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var registryConfig = new(RegistryConfig)
+	if err := json.Unmarshal(resp, registryConfig); err != nil {
+		return nil, err
+	}
+	return registryConfig, nil
+}
+
 // GetCompatibilityLevel returns the compatibility level of the subject.
 // If defaultToGlobal is set to true and no compatibility level is set on the subject, the global compatibility level is returned.
 func (client *SchemaRegistryClient) GetCompatibilityLevel(subject string, defaultToGlobal bool) (*CompatibilityLevel, error) {
-	resp, err := client.httpRequest("GET", fmt.Sprintf(configBySubject+"?defaultToGlobal=%t", url.QueryEscape(subject), defaultToGlobal), nil)
+	resp, err := client.httpRequest("GET", fmt.Sprintf(configBySubject+"?defaultToGlobal=%t", url.PathEscape(subject), defaultToGlobal), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -409,9 +1508,130 @@ func (client *SchemaRegistryClient) GetCompatibilityLevel(subject string, defaul
 	return &configResponse.CompatibilityLevel, nil
 }
 
+// GetCompatibilityLevels returns the compatibility level of every subject, fetched concurrently,
+// as many at once as the semaphore weight allows. Subjects are handled like GetCompatibilityLevel
+// does. If some levels can't be fetched, the ones that could are returned along with a
+// *CompatibilityLevelsError holding the failures.
+func (client *SchemaRegistryClient) GetCompatibilityLevels(subjects []string, defaultToGlobal bool) (map[string]CompatibilityLevel, error) {
+	levels := make(map[string]CompatibilityLevel, len(subjects))
+	errs := make(map[string]error)
+	seen := make(map[string]bool, len(subjects))
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, subject := range subjects {
+		if seen[subject] {
+			continue
+		}
+		seen[subject] = true
+
+		wg.Add(1)
+		go func(subject string) {
+			defer wg.Done()
+			// GetCompatibilityLevel waits for the semaphore before sending the request
+			level, err := client.GetCompatibilityLevel(subject, defaultToGlobal)
+
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs[subject] = err
+				return
+			}
+			levels[subject] = *level
+		}(subject)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return levels, &CompatibilityLevelsError{Errors: errs}
+	}
+	return levels, nil
+}
+
+// GetEffectiveCompatibility returns the compatibility level that applies to the subject.
+// inherited is set to true when the subject has no compatibility level of its own, in
+// which case the global compatibility level is returned.
+func (client *SchemaRegistryClient) GetEffectiveCompatibility(subject string) (level CompatibilityLevel, inherited bool, err error) {
+	compatibility, err := client.GetCompatibilityLevel(subject, false)
+	if err == nil {
+		return *compatibility, false, nil
+	}
+	if !errors.Is(err, ErrCompatibilityNotConfigured) {
+		return "", false, err
+	}
+
+	compatibility, err = client.GetGlobalCompatibilityLevel()
+	if err != nil {
+		return "", false, err
+	}
+	return *compatibility, true, nil
+}
+
+// GetGlobalMode returns the global mode of the registry.
+func (client *SchemaRegistryClient) GetGlobalMode() (Mode, error) {
+	resp, err := client.httpRequest("GET", mode, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var modeResp = new(modeResponse)
+	if err := json.Unmarshal(resp, &modeResp); err != nil {
+		return "", err
+	}
+
+	return modeResp.Mode, nil
+}
+
+// GetMode returns the mode of the subject.
+func (client *SchemaRegistryClient) GetMode(subject string) (Mode, error) {
+	resp, err := client.httpRequest("GET", fmt.Sprintf(modeBySubject, url.PathEscape(subject)), nil)
+	if err != nil {
+		return "", err
+	}
+
+	var modeResp = new(modeResponse)
+	if err := json.Unmarshal(resp, &modeResp); err != nil {
+		return "", err
+	}
+
+	return modeResp.Mode, nil
+}
+
+// UpdateMode changes the mode of the subject. Setting force to true allows
+// switching a subject that already has schemas registered into IMPORT mode.
+func (client *SchemaRegistryClient) UpdateMode(subject string, mode Mode, force bool) (Mode, error) {
+	modeReqBytes, err := json.Marshal(modeRequest{Mode: mode})
+	if err != nil {
+		return "", err
+	}
+	payload := bytes.NewBuffer(modeReqBytes)
+
+	uri := fmt.Sprintf(modeBySubject, url.PathEscape(subject))
+	if force {
+		uri += "?force=true"
+	}
+	resp, err := client.httpRequest("PUT", uri, payload)
+	if err != nil {
+		return "", err
+	}
+
+	var modeResp = new(modeResponse)
+	if err := json.Unmarshal(resp, &modeResp); err != nil {
+		return "", err
+	}
+
+	return modeResp.Mode, nil
+}
+
 // GetSubjects returns a list of all subjects in the registry
 func (client *SchemaRegistryClient) GetSubjects() ([]string, error) {
-	resp, err := client.httpRequest("GET", subjects, nil)
+	return client.GetSubjectsWithContext(context.Background())
+}
+
+// GetSubjectsWithContext is GetSubjects bound to the context. If the context has a
+// deadline, it replaces the client timeout for this call, see SetTimeout.
+func (client *SchemaRegistryClient) GetSubjectsWithContext(ctx context.Context) ([]string, error) {
+	resp, err := client.httpRequestWithContext(ctx, "GET", subjects, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -438,10 +1658,426 @@ func (client *SchemaRegistryClient) GetSubjectsIncludingDeleted() ([]string, err
 	return allSubjects, nil
 }
 
+// StreamSubjects calls fn with every subject in the registry as the response is read, so large
+// registries can be processed without holding all their subjects in memory. It stops as soon as
+// fn returns an error, which is returned as is.
+func (client *SchemaRegistryClient) StreamSubjects(fn func(subject string) error) error {
+	return client.streamHTTPRequest("GET", subjects, func(body io.Reader) error {
+		decoder := json.NewDecoder(body)
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+		for decoder.More() {
+			var subject string
+			if err := decoder.Decode(&subject); err != nil {
+				return err
+			}
+			if err := fn(subject); err != nil {
+				return err
+			}
+		}
+		_, err := decoder.Token()
+		return err
+	})
+}
+
+// GetSubjectsByPrefix returns the subjects starting with the given prefix, which are
+// filtered by Schema Registry. Soft deleted subjects are included if includeDeleted is true.
+func (client *SchemaRegistryClient) GetSubjectsByPrefix(prefix string, includeDeleted bool) ([]string, error) {
+	query := url.Values{}
+	query.Set("subjectPrefix", prefix)
+	if includeDeleted {
+		query.Set("deleted", "true")
+	}
+	resp, err := client.httpRequest("GET", subjects+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var allSubjects []string
+	if err = json.Unmarshal(resp, &allSubjects); err != nil {
+		return nil, err
+	}
+
+	return allSubjects, nil
+}
+
+// GetAllSchemas returns the schemas registered under every subject. If latestOnly
+// is set to true only the latest version of each subject is returned. Offset and
+// limit allow paging through large registries, a zero limit returns all schemas.
+func (client *SchemaRegistryClient) GetAllSchemas(latestOnly bool, offset, limit int) ([]SchemaMetadata, error) {
+	return client.GetAllSchemasWithContext(context.Background(), latestOnly, offset, limit)
+}
+
+// GetAllSchemasWithContext is GetAllSchemas bound to the context. If the context has
+// a deadline, it replaces the client timeout for this call, see SetTimeout.
+func (client *SchemaRegistryClient) GetAllSchemasWithContext(ctx context.Context, latestOnly bool, offset, limit int) ([]SchemaMetadata, error) {
+	query := url.Values{}
+	if latestOnly {
+		query.Set("latestOnly", "true")
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	uri := schemas
+	if len(query) > 0 {
+		uri += "?" + query.Encode()
+	}
+	resp, err := client.httpRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var allSchemas []SchemaMetadata
+	if err = json.Unmarshal(resp, &allSchemas); err != nil {
+		return nil, err
+	}
+
+	// schemaType is omitted by Schema Registry for Avro schemas
+	for i := range allSchemas {
+		if allSchemas[i].SchemaType == "" {
+			allSchemas[i].SchemaType = Avro
+		}
+	}
+
+	return allSchemas, nil
+}
+
+// GetMaxSchemaID returns the highest ID among the schemas registered in Schema Registry,
+// including the soft deleted ones, or 0 if there are none. This is the ceiling to stay
+// above when importing schemas with their IDs, e.g. in IMPORT mode, to avoid collisions.
+func (client *SchemaRegistryClient) GetMaxSchemaID() (int, error) {
+	resp, err := client.httpRequest("GET", schemas+"?deleted=true", nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var allSchemas []SchemaMetadata
+	if err = json.Unmarshal(resp, &allSchemas); err != nil {
+		return 0, err
+	}
+
+	var maxID int
+	for _, schema := range allSchemas {
+		if schema.ID > maxID {
+			maxID = schema.ID
+		}
+	}
+	return maxID, nil
+}
+
+// FindSubjectsWithSchema returns the subject and version of every registration of the
+// schema across the registry, which helps finding duplicate schemas. Schema Registry
+// has no endpoint for this, so every schema is listed with GetAllSchemas and compared
+// with the given one, ignoring whitespace.
+func (client *SchemaRegistryClient) FindSubjectsWithSchema(schema string, schemaType SchemaType) (SubjectVersionResponse, error) {
+	return findSubjectsWithSchema(client, schema, schemaType)
+}
+
+// findSubjectsWithSchema lists every schema of the registry
+// to find the versions registered with the given schema.
+func findSubjectsWithSchema(client ISchemaRegistryClient, schema string, schemaType SchemaType) (SubjectVersionResponse, error) {
+	allSchemas, err := client.GetAllSchemas(false, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := normalizeSchemaWhitespace(schema, schemaType)
+	matches := make(SubjectVersionResponse, 0)
+	for _, metadata := range allSchemas {
+		if metadata.SchemaType != schemaType || normalizeSchemaWhitespace(metadata.Schema, schemaType) != normalized {
+			continue
+		}
+		matches = append(matches, subjectVersionPair{Subject: metadata.Subject, Version: metadata.Version})
+	}
+	return matches, nil
+}
+
+// normalizeSchemaWhitespace removes the whitespace that isn't significant in the schema.
+// Avro and Json schemas are compacted as JSON, while runs of whitespace are collapsed into
+// a single space in Protobuf schemas, and in the others if they aren't valid JSON.
+func normalizeSchemaWhitespace(schema string, schemaType SchemaType) string {
+	if schemaType == Avro || schemaType == Json {
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, []byte(schema)); err == nil {
+			return compacted.String()
+		}
+	}
+	return strings.Join(strings.Fields(schema), " ")
+}
+
 // GetSchemaByVersion gets the schema associated with the given subject.
 // The schema returned contains the version specified as a parameter.
 func (client *SchemaRegistryClient) GetSchemaByVersion(subject string, version int) (*Schema, error) {
-	return client.getVersion(subject, strconv.Itoa(version))
+	return client.GetSchemaByVersionWithContext(context.Background(), subject, version)
+}
+
+// GetSchemaByVersionWithContext is GetSchemaByVersion bound to the context. The call
+// returns as soon as the context is done, like GetSchemaWithContext, and concurrent
+// calls for the same version share a single request, bound by the client timeout.
+func (client *SchemaRegistryClient) GetSchemaByVersionWithContext(ctx context.Context, subject string, version int) (*Schema, error) {
+	return client.getVersion(ctx, subject, strconv.Itoa(version))
+}
+
+// GetSchemaByVersionString gets the schema of the subject with the given version,
+// which is either "latest" or a version number. It shares its cache entries with
+// GetLatestSchema and GetSchemaByVersion.
+func (client *SchemaRegistryClient) GetSchemaByVersionString(subject string, version string) (*Schema, error) {
+	version, err := parseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return client.getVersion(context.Background(), subject, version)
+}
+
+// parseVersion normalizes the version of a subject given as a string, so the same version
+// is always cached under the same key. -1 is an alias of "latest" in Schema Registry.
+func parseVersion(version string) (string, error) {
+	if strings.EqualFold(version, "latest") {
+		return "latest", nil
+	}
+	number, err := strconv.Atoi(version)
+	if err != nil || number == 0 || number < -1 {
+		return "", fmt.Errorf("invalid version %q: it must be \"latest\" or a version number", version)
+	}
+	if number == -1 {
+		return "latest", nil
+	}
+	return strconv.Itoa(number), nil
+}
+
+// GetSchemaByVersionIncludingDeleted works like GetSchemaByVersion, but also gets
+// versions that have been soft deleted. The schema is not cached, so a soft deleted
+// version isn't returned from the cache by GetSchemaByVersion afterwards.
+func (client *SchemaRegistryClient) GetSchemaByVersionIncludingDeleted(subject string, version int) (*Schema, error) {
+	uri := fmt.Sprintf(subjectByVersion, url.PathEscape(subject), strconv.Itoa(version)) + "?deleted=true"
+	resp, err := client.httpRequest("GET", uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaResp := new(schemaResponse)
+	if err = json.Unmarshal(resp, &schemaResp); err != nil {
+		return nil, err
+	}
+	var codec *goavro.Codec
+	if client.getCodecCreationEnabled() {
+		codec, err = client.getCodecForSchema(schemaResp.Schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Schema{
+		id:         schemaResp.ID,
+		schema:     schemaResp.Schema,
+		schemaType: schemaResp.SchemaType,
+		version:    schemaResp.Version,
+		references: schemaResp.References,
+		metadata:   schemaResp.Metadata,
+		ruleSet:    schemaResp.RuleSet,
+		codec:      codec,
+	}, nil
+}
+
+// GetSchemasByIDs gets the schemas with the given IDs concurrently, as many at
+// once as the semaphore weight allows. If some schemas can't be fetched, the ones
+// that could are returned along with a *SchemasByIDsError holding the failures.
+func (client *SchemaRegistryClient) GetSchemasByIDs(schemaIDs []int) (map[int]*Schema, error) {
+	schemas := make(map[int]*Schema, len(schemaIDs))
+	errs := make(map[int]error)
+	seen := make(map[int]bool, len(schemaIDs))
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, schemaID := range schemaIDs {
+		if seen[schemaID] {
+			continue
+		}
+		seen[schemaID] = true
+
+		wg.Add(1)
+		go func(schemaID int) {
+			defer wg.Done()
+			// GetSchema waits for the semaphore before sending the request
+			schema, err := client.GetSchema(schemaID)
+
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs[schemaID] = err
+				return
+			}
+			schemas[schemaID] = schema
+		}(schemaID)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return schemas, &SchemasByIDsError{Errors: errs}
+	}
+	return schemas, nil
+}
+
+// GetAllVersionsForSubject gets every version of the subject, sorted by version. The
+// versions are fetched concurrently, as many at once as the semaphore weight allows.
+// If some versions can't be fetched, the ones that could are returned along with a
+// *SchemaVersionsError holding the failures.
+func (client *SchemaRegistryClient) GetAllVersionsForSubject(subject string) ([]*Schema, error) {
+	versions, err := client.GetSchemaVersions(subject)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make([]*Schema, 0, len(versions))
+	errs := make(map[int]error)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, version := range versions {
+		wg.Add(1)
+		go func(version int) {
+			defer wg.Done()
+			// GetSchemaByVersion waits for the semaphore before sending the request
+			schema, err := client.GetSchemaByVersion(subject, version)
+
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs[version] = err
+				return
+			}
+			schemas = append(schemas, schema)
+		}(version)
+	}
+	wg.Wait()
+
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].version < schemas[j].version
+	})
+	if len(errs) > 0 {
+		return schemas, &SchemaVersionsError{Subject: subject, Errors: errs}
+	}
+	return schemas, nil
+}
+
+// ResolveVersionByContent returns the version of the subject registered with the schema,
+// comparing it with every version of the subject while ignoring whitespace. Unlike
+// FindSchemaVersion, it only sends GET requests, for registries where the lookup POST is
+// forbidden. The versions are fetched like GetAllVersionsForSubject does, and the error
+// matches ErrSchemaNotFound if no version matches.
+func (client *SchemaRegistryClient) ResolveVersionByContent(subject string, schema string, schemaType SchemaType) (int, error) {
+	return resolveVersionByContent(client, subject, schema, schemaType)
+}
+
+// resolveVersionByContent fetches every version of the subject to find the
+// earliest one registered with the schema. Versions that can't be fetched only
+// fail the call if none of the others match.
+func resolveVersionByContent(client ISchemaRegistryClient, subject string, schema string, schemaType SchemaType) (int, error) {
+	versions, err := client.GetAllVersionsForSubject(subject)
+	var versionsErr *SchemaVersionsError
+	if err != nil && !errors.As(err, &versionsErr) {
+		return 0, err
+	}
+
+	normalized := normalizeSchemaWhitespace(schema, schemaType)
+	for _, version := range versions {
+		versionType := Avro
+		if version.SchemaType() != nil {
+			versionType = *version.SchemaType()
+		}
+		if versionType == schemaType && normalizeSchemaWhitespace(version.Schema(), schemaType) == normalized {
+			return version.Version(), nil
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("%w: no version of subject %s matches the schema", ErrSchemaNotFound, subject)
+}
+
+// ReimportSubject registers every version of the subject into the target client, in the
+// order of their versions, e.g. to migrate it to another registry or to register it again
+// after deleting it. Nothing is registered if some version can't be read, and registering
+// stops at the first version the target rejects.
+func (client *SchemaRegistryClient) ReimportSubject(targetClient ISchemaRegistryClient, subject string) error {
+	return reimportSubject(client, targetClient, subject)
+}
+
+// reimportSubject registers every version of the subject read from the source into the target.
+func reimportSubject(sourceClient, targetClient ISchemaRegistryClient, subject string) error {
+	schemas, err := sourceClient.GetAllVersionsForSubject(subject)
+	if err != nil {
+		return err
+	}
+	for _, schema := range schemas {
+		schemaType := Avro
+		if schema.schemaType != nil {
+			schemaType = *schema.schemaType
+		}
+		if _, err := targetClient.CreateSchema(subject, schema.schema, schemaType, schema.references...); err != nil {
+			return fmt.Errorf("failed to reimport version %d of %s: %w", schema.version, subject, err)
+		}
+	}
+	return nil
+}
+
+// GetSchemaExpanded gets the schema with the given ID with the named types of its
+// references, and of theirs, inlined where they're first used. This allows using it
+// with tools that don't know about Schema Registry. Only Avro schemas can be expanded,
+// and references that end up referencing themselves return ErrReferenceCycle.
+func (client *SchemaRegistryClient) GetSchemaExpanded(schemaID int) (string, error) {
+	schema, err := client.GetSchema(schemaID)
+	if err != nil {
+		return "", err
+	}
+	return expandSchema(client, schema)
+}
+
+// DiffSchemaVersions gets both versions of the subject and returns the fields added,
+// removed and modified between them, e.g. to write a changelog of its evolution. Only
+// Avro records can be diffed, other schemas return ErrDiffUnsupported.
+func (client *SchemaRegistryClient) DiffSchemaVersions(subject string, fromVersion, toVersion int) (SchemaDiff, error) {
+	return diffSchemaVersions(client, subject, fromVersion, toVersion)
+}
+
+// GetSchemaTypes returns the schema types supported by Schema Registry,
+// which depend on its version and the schema providers it's configured with.
+func (client *SchemaRegistryClient) GetSchemaTypes() ([]SchemaType, error) {
+	resp, err := client.httpRequest("GET", schemaTypes, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var types []string
+	if err = json.Unmarshal(resp, &types); err != nil {
+		return nil, err
+	}
+
+	supportedTypes := make([]SchemaType, 0, len(types))
+	for _, schemaType := range types {
+		supportedTypes = append(supportedTypes, SchemaType(schemaType))
+	}
+	return supportedTypes, nil
+}
+
+// GetReferencedBy returns the IDs of the schemas that reference the given
+// version of the subject, which prevent it from being deleted.
+func (client *SchemaRegistryClient) GetReferencedBy(subject string, version int) ([]int, error) {
+	resp, err := client.httpRequest("GET", fmt.Sprintf(referencedBy, url.PathEscape(subject), version), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	if err = json.Unmarshal(resp, &ids); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
 }
 
 // CreateSchema creates a new schema in Schema Registry and associates
@@ -449,27 +2085,215 @@ func (client *SchemaRegistryClient) GetSchemaByVersion(subject string, version i
 // all its associated information.
 func (client *SchemaRegistryClient) CreateSchema(subject string, schema string,
 	schemaType SchemaType, references ...Reference) (*Schema, error) {
+	return client.createSchema(subject, schemaType, false,
+		schemaRequest{Schema: schema, References: references})
+}
+
+// RegisterSchemaWithUpdatedReferences works like CreateSchema, but first makes sure the
+// subject version of every reference exists, which catches references to deleted versions.
+// If some don't, nothing is registered and a *DanglingReferencesError lists them.
+func (client *SchemaRegistryClient) RegisterSchemaWithUpdatedReferences(subject string, schema string,
+	schemaType SchemaType, references []Reference) (*Schema, error) {
+	return registerWithCheckedReferences(client, subject, schema, schemaType, references)
+}
+
+// registerWithCheckedReferences creates the schema once it has checked its references exist.
+func registerWithCheckedReferences(client ISchemaRegistryClient, subject string, schema string,
+	schemaType SchemaType, references []Reference) (*Schema, error) {
+	var dangling []Reference
+	for _, reference := range references {
+		_, err := client.GetSchemaByVersion(reference.Subject, reference.Version)
+		if errors.Is(err, ErrSubjectNotFound) || errors.Is(err, ErrVersionNotFound) || errors.Is(err, ErrSchemaNotFound) {
+			dangling = append(dangling, reference)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if len(dangling) > 0 {
+		return nil, &DanglingReferencesError{References: dangling}
+	}
+
+	return client.CreateSchema(subject, schema, schemaType, references...)
+}
+
+// CreateSchemaFromFile works like CreateSchema, but reads the schema from the file, inferring
+// its type from the extension: .avsc for Avro, .proto for Protobuf and .json for Json. Files
+// with other extensions need their type to be given with CreateSchemaFromFileWithType.
+func (client *SchemaRegistryClient) CreateSchemaFromFile(subject string, path string,
+	references ...Reference) (*Schema, error) {
+	return createSchemaFromFile(client, subject, path, "", references)
+}
+
+// CreateSchemaFromFileWithType works like CreateSchema, but reads the schema from the file.
+func (client *SchemaRegistryClient) CreateSchemaFromFileWithType(subject string, path string,
+	schemaType SchemaType, references ...Reference) (*Schema, error) {
+	return createSchemaFromFile(client, subject, path, schemaType, references)
+}
+
+// createSchemaFromFile creates the schema read from the file, with
+// its type inferred from the extension if schemaType is empty.
+func createSchemaFromFile(client ISchemaRegistryClient, subject string, path string,
+	schemaType SchemaType, references []Reference) (*Schema, error) {
+	if schemaType == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".avsc":
+			schemaType = Avro
+		case ".proto":
+			schemaType = Protobuf
+		case ".json":
+			schemaType = Json
+		default:
+			return nil, fmt.Errorf("can't infer the schema type of %s from its extension, "+
+				"use CreateSchemaFromFileWithType to specify it", path)
+		}
+	}
+
+	schema, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return client.CreateSchema(subject, string(schema), schemaType, references...)
+}
+
+// CreateSchemaNormalized works like CreateSchema, but asks Schema Registry
+// to normalize the schema first, so schemas that only differ in their
+// formatting are not registered as new versions.
+func (client *SchemaRegistryClient) CreateSchemaNormalized(subject string, schema string,
+	schemaType SchemaType, references ...Reference) (*Schema, error) {
+	return client.createSchema(subject, schemaType, true,
+		schemaRequest{Schema: schema, References: references})
+}
+
+// CreateSchemaWithCompatibility works like CreateSchema, but first sets the compatibility
+// level of the subject, so even its first schema is registered under that level. If the
+// level can't be set, the schema isn't registered.
+func (client *SchemaRegistryClient) CreateSchemaWithCompatibility(subject string, schema string,
+	schemaType SchemaType, compatibility CompatibilityLevel, references ...Reference) (*Schema, error) {
+	if _, err := client.ChangeSubjectCompatibilityLevel(subject, compatibility); err != nil {
+		return nil, err
+	}
+	return client.CreateSchema(subject, schema, schemaType, references...)
+}
+
+// CreateSchemaAndListVersions works like CreateSchema, and also returns the versions of the
+// subject right after the schema is registered, sorted in ascending order, e.g. to prune the
+// older ones. Schema Registry can't do both at once, so the versions may include some
+// registered concurrently after the schema, but they always include the schema's version.
+func (client *SchemaRegistryClient) CreateSchemaAndListVersions(subject string, schema string,
+	schemaType SchemaType, references ...Reference) (*Schema, []int, error) {
+	created, err := client.CreateSchema(subject, schema, schemaType, references...)
+	if err != nil {
+		return nil, nil, err
+	}
+	versions, err := client.GetSchemaVersions(subject)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Ints(versions)
+	return created, versions, nil
+}
+
+// CreateSchemaIdempotent works like CreateSchema, and also reports whether the schema was
+// registered by the call, as opposed to being already registered under the subject. The
+// schema is looked up first, so it can be reported as registered by the call if another
+// client registers it between the lookup and the registration.
+func (client *SchemaRegistryClient) CreateSchemaIdempotent(subject string, schema string,
+	schemaType SchemaType, references ...Reference) (*Schema, bool, error) {
+	existing, err := client.LookupSchema(subject, schema, schemaType, references...)
+	if err == nil {
+		return existing, false, nil
+	}
+	if !errors.Is(err, ErrSchemaNotFound) {
+		return nil, false, err
+	}
+
+	created, err := client.CreateSchema(subject, schema, schemaType, references...)
+	if err != nil {
+		return nil, false, err
+	}
+	return created, true, nil
+}
+
+// CreateSchemaWithID creates a new schema in Schema Registry with the
+// given id and version, and associates it with the subject provided.
+// This allows replicating the IDs of another registry, and requires
+// the subject to be in IMPORT mode. Zero id or version are omitted,
+// leaving it up to Schema Registry to assign them.
+func (client *SchemaRegistryClient) CreateSchemaWithID(subject string, schema string,
+	schemaType SchemaType, id int, version int, references ...Reference) (*Schema, error) {
+	return client.createSchema(subject, schemaType, false,
+		schemaRequest{Schema: schema, References: references, ID: id, Version: version})
+}
+
+// ImportSchema registers the schema under the subject with the given id and version, like
+// CreateSchemaWithID, so migrations can be run again after failing halfway. If Schema Registry
+// rejects the registration, the schema is looked up under the subject, and returned if it's
+// already registered there with the same id and version. The subject must be put in IMPORT
+// mode first, e.g. with UpdateMode(subject, Import, true), or every registration is rejected.
+func (client *SchemaRegistryClient) ImportSchema(subject string, schema string,
+	schemaType SchemaType, id int, version int, references ...Reference) (*Schema, error) {
+	created, err := client.CreateSchemaWithID(subject, schema, schemaType, id, version, references...)
+	var registryErr Error
+	if err == nil || !errors.As(err, &registryErr) {
+		return created, err
+	}
+
+	existing, lookupErr := client.LookupSchema(subject, schema, schemaType, references...)
+	if lookupErr != nil || !isImported(existing, id, version) {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// isImported reports whether the schema was registered with the id and version
+// given to ImportSchema, where zero means Schema Registry was left to assign them.
+func isImported(schema *Schema, id int, version int) bool {
+	return (id == 0 || schema.id == id) && (version == 0 || schema.version == version)
+}
+
+// CreateSchemaWithConfig works like CreateSchema, but also registers
+// the metadata and rule set of the request, used by data contracts.
+func (client *SchemaRegistryClient) CreateSchemaWithConfig(subject string, req RegisterSchemaRequest) (*Schema, error) {
+	return client.createSchema(subject, req.SchemaType, false, schemaRequest{
+		Schema:     req.Schema,
+		References: req.References,
+		Metadata:   req.Metadata,
+		RuleSet:    req.RuleSet,
+	})
+}
+
+func (client *SchemaRegistryClient) createSchema(subject string,
+	schemaType SchemaType, normalize bool, schemaReq schemaRequest) (*Schema, error) {
 	switch schemaType {
 	case Avro, Json:
-		compiledRegex := regexp.MustCompile(`\r?\n`)
-		schema = compiledRegex.ReplaceAllString(schema, " ")
+		if client.stripSchemaNewlines {
+			compiledRegex := regexp.MustCompile(`\r?\n`)
+			schemaReq.Schema = compiledRegex.ReplaceAllString(schemaReq.Schema, " ")
+		}
 	case Protobuf:
 		break
 	default:
 		return nil, fmt.Errorf("invalid schema type. valid values are Avro, Json, or Protobuf")
 	}
+	if err := client.validateSchema(subject, schemaReq.Schema, schemaType); err != nil {
+		return nil, err
+	}
 
-	if references == nil {
-		references = make([]Reference, 0)
+	if schemaReq.References == nil {
+		schemaReq.References = make([]Reference, 0)
 	}
 
-	schemaReq := schemaRequest{Schema: schema, SchemaType: schemaType.String(), References: references}
+	schemaReq.SchemaType = schemaType.String()
 	schemaBytes, err := json.Marshal(schemaReq)
 	if err != nil {
 		return nil, err
 	}
 	payload := bytes.NewBuffer(schemaBytes)
-	resp, err := client.httpRequest("POST", fmt.Sprintf(subjectVersions, url.QueryEscape(subject)), payload)
+	uri := fmt.Sprintf(subjectVersions, url.PathEscape(subject))
+	if normalize {
+		uri += "?normalize=true"
+	}
+	resp, err := client.httpRequest("POST", uri, payload)
 	if err != nil {
 		return nil, err
 	}
@@ -480,7 +2304,7 @@ func (client *SchemaRegistryClient) CreateSchema(subject string, schema string,
 		return nil, err
 	}
 
-	newSchema, err := client.GetSchema(schemaResp.ID)
+	newSchema, err := client.getCreatedSchema(context.Background(), schemaResp.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -490,14 +2314,10 @@ func (client *SchemaRegistryClient) CreateSchema(subject string, schema string,
 		// Update the subject-2-schema cache
 		cacheKey := cacheKey(subject,
 			strconv.Itoa(newSchema.version))
-		client.subjectSchemaCacheLock.Lock()
-		client.subjectSchemaCache[cacheKey] = newSchema
-		client.subjectSchemaCacheLock.Unlock()
+		client.cacheSchemaBySubject(cacheKey, newSchema)
 
 		// Update the id-2-schema cache
-		client.idSchemaCacheLock.Lock()
-		client.idSchemaCache[newSchema.id] = newSchema
-		client.idSchemaCacheLock.Unlock()
+		client.cacheSchemaByID(newSchema.id, newSchema)
 
 	}
 
@@ -505,34 +2325,19 @@ func (client *SchemaRegistryClient) CreateSchema(subject string, schema string,
 }
 
 // LookupSchema looks up the schema by subject and schema string. If it finds the schema it returns it with all its associated information.
+// If the schema isn't registered under the subject, or the subject doesn't exist, the error matches ErrSchemaNotFound.
 func (client *SchemaRegistryClient) LookupSchema(subject string, schema string, schemaType SchemaType, references ...Reference) (*Schema, error) {
-	switch schemaType {
-	case Avro, Json:
-		compiledRegex := regexp.MustCompile(`\r?\n`)
-		schema = compiledRegex.ReplaceAllString(schema, " ")
-	case Protobuf:
-		break
-	default:
-		return nil, fmt.Errorf("invalid schema type. valid values are Avro, Json, or Protobuf")
-	}
-
-	if references == nil {
-		references = make([]Reference, 0)
-	}
+	return client.lookupSchema(subject, schema, schemaType, false, references)
+}
 
-	schemaReq := schemaRequest{Schema: schema, SchemaType: schemaType.String(), References: references}
-	schemaBytes, err := json.Marshal(schemaReq)
-	if err != nil {
-		return nil, err
-	}
-	payload := bytes.NewBuffer(schemaBytes)
-	resp, err := client.httpRequest("POST", fmt.Sprintf(subjectBySubject, url.QueryEscape(subject)), payload)
-	if err != nil {
-		return nil, err
-	}
+// LookupSchemaNormalized works like LookupSchema, but asks Schema Registry to normalize
+// the schema first, so it's found even if it differs in formatting from the registered one.
+func (client *SchemaRegistryClient) LookupSchemaNormalized(subject string, schema string, schemaType SchemaType, references ...Reference) (*Schema, error) {
+	return client.lookupSchema(subject, schema, schemaType, true, references)
+}
 
-	schemaResp := new(schemaResponse)
-	err = json.Unmarshal(resp, &schemaResp)
+func (client *SchemaRegistryClient) lookupSchema(subject string, schema string, schemaType SchemaType, normalize bool, references []Reference) (*Schema, error) {
+	schemaResp, err := client.postLookup(subject, schema, schemaType, normalize, references)
 	if err != nil {
 		return nil, err
 	}
@@ -550,6 +2355,8 @@ func (client *SchemaRegistryClient) LookupSchema(subject string, schema string,
 		schemaType: schemaResp.SchemaType,
 		version:    schemaResp.Version,
 		references: schemaResp.References,
+		metadata:   schemaResp.Metadata,
+		ruleSet:    schemaResp.RuleSet,
 		codec:      codec,
 	}
 
@@ -558,23 +2365,161 @@ func (client *SchemaRegistryClient) LookupSchema(subject string, schema string,
 		// Update the subject-2-schema cache
 		cacheKey := cacheKey(subject,
 			strconv.Itoa(gotSchema.version))
-		client.subjectSchemaCacheLock.Lock()
-		client.subjectSchemaCache[cacheKey] = gotSchema
-		client.subjectSchemaCacheLock.Unlock()
+		client.cacheSchemaBySubject(cacheKey, gotSchema)
 
 		// Update the id-2-schema cache
-		client.idSchemaCacheLock.Lock()
-		client.idSchemaCache[gotSchema.id] = gotSchema
-		client.idSchemaCacheLock.Unlock()
+		client.cacheSchemaByID(gotSchema.id, gotSchema)
 
 	}
 
 	return gotSchema, nil
 }
 
+// FindSchemaVersion looks up the schema by subject and schema string, like LookupSchema,
+// but only returns the version and ID it's registered with. If the schema isn't registered
+// under the subject, or the subject doesn't exist, the error matches ErrSchemaNotFound.
+func (client *SchemaRegistryClient) FindSchemaVersion(subject string, schema string, schemaType SchemaType, references ...Reference) (int, int, error) {
+	schemaResp, err := client.postLookup(subject, schema, schemaType, false, references)
+	if err != nil {
+		return 0, 0, err
+	}
+	return schemaResp.Version, schemaResp.ID, nil
+}
+
+// postLookup posts the schema to the subject to find the version it's registered with.
+func (client *SchemaRegistryClient) postLookup(subject string, schema string, schemaType SchemaType, normalize bool, references []Reference) (*schemaResponse, error) {
+	switch schemaType {
+	case Avro, Json:
+		if client.stripSchemaNewlines {
+			compiledRegex := regexp.MustCompile(`\r?\n`)
+			schema = compiledRegex.ReplaceAllString(schema, " ")
+		}
+	case Protobuf:
+		break
+	default:
+		return nil, fmt.Errorf("invalid schema type. valid values are Avro, Json, or Protobuf")
+	}
+	if err := client.validateSchema(subject, schema, schemaType); err != nil {
+		return nil, err
+	}
+
+	if references == nil {
+		references = make([]Reference, 0)
+	}
+
+	schemaReq := schemaRequest{Schema: schema, SchemaType: schemaType.String(), References: references}
+	schemaBytes, err := json.Marshal(schemaReq)
+	if err != nil {
+		return nil, err
+	}
+	payload := bytes.NewBuffer(schemaBytes)
+	uri := fmt.Sprintf(subjectBySubject, url.PathEscape(subject))
+	if normalize {
+		uri += "?normalize=true"
+	}
+	resp, err := client.httpRequest("POST", uri, payload)
+	if err != nil {
+		if registryErr, ok := err.(Error); ok {
+			// No schema is registered under a subject that doesn't exist
+			registryErr.lookup = true
+			return nil, registryErr
+		}
+		return nil, err
+	}
+
+	schemaResp := new(schemaResponse)
+	err = json.Unmarshal(resp, &schemaResp)
+	if err != nil {
+		return nil, err
+	}
+	return schemaResp, nil
+}
+
+// validateSchema runs the validator set with WithSchemaValidator, if any.
+func (client *SchemaRegistryClient) validateSchema(subject string, schema string, schemaType SchemaType) error {
+	if client.schemaValidator == nil {
+		return nil
+	}
+	return client.schemaValidator(subject, schema, schemaType)
+}
+
 // IsSchemaCompatible checks if the given schema is compatible with the given subject and version
 // valid versions are versionID and "latest"
 func (client *SchemaRegistryClient) IsSchemaCompatible(subject, schema, version string, schemaType SchemaType, references ...Reference) (bool, error) {
+	compatibilityResponse, err := client.checkCompatibility(subject, schema, version, schemaType, false, references)
+	if err != nil {
+		return false, err
+	}
+
+	return compatibilityResponse.IsCompatible, nil
+}
+
+// CheckSchemaCompatibility works like IsSchemaCompatible, but also returns
+// the messages explaining why the schema isn't compatible, if it isn't.
+func (client *SchemaRegistryClient) CheckSchemaCompatibility(subject, schema, version string, schemaType SchemaType, references ...Reference) (CompatibilityResult, error) {
+	compatibilityResponse, err := client.checkCompatibility(subject, schema, version, schemaType, true, references)
+	if err != nil {
+		return CompatibilityResult{}, err
+	}
+
+	return CompatibilityResult{
+		IsCompatible: compatibilityResponse.IsCompatible,
+		Messages:     compatibilityResponse.Messages,
+	}, nil
+}
+
+// IsSchemaCompatibleWithAll checks if the given schema is compatible with every version of the
+// given subject at once, which is what transitive compatibility levels require.
+func (client *SchemaRegistryClient) IsSchemaCompatibleWithAll(subject, schema string, schemaType SchemaType, references ...Reference) (bool, error) {
+	compatibilityResponse, err := client.checkCompatibility(subject, schema, "", schemaType, false, references)
+	if err != nil {
+		return false, err
+	}
+
+	return compatibilityResponse.IsCompatible, nil
+}
+
+// CheckSchemasCompatible checks the compatibility of every candidate with the subject
+// concurrently, as many at once as the semaphore weight allows, and returns the results
+// in the order of the candidates. If some checks fail, the results of the others are
+// returned along with a *CompatibilityChecksError holding the failures.
+func (client *SchemaRegistryClient) CheckSchemasCompatible(subject string, candidates []SchemaCandidate) ([]CompatibilityResult, error) {
+	results := make([]CompatibilityResult, len(candidates))
+	errs := make(map[int]error)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+
+	for index, candidate := range candidates {
+		wg.Add(1)
+		go func(index int, candidate SchemaCandidate) {
+			defer wg.Done()
+			// checkCompatibility waits for the semaphore before sending the request
+			compatibilityResponse, err := client.checkCompatibility(subject, candidate.Schema, candidate.Version,
+				candidate.SchemaType, true, candidate.References)
+			if err != nil {
+				lock.Lock()
+				errs[index] = err
+				lock.Unlock()
+				return
+			}
+			results[index] = CompatibilityResult{
+				IsCompatible: compatibilityResponse.IsCompatible,
+				Messages:     compatibilityResponse.Messages,
+			}
+		}(index, candidate)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return results, &CompatibilityChecksError{Errors: errs}
+	}
+	return results, nil
+}
+
+// checkCompatibility checks the schema against the given version of
+// the subject, or against all its versions if version is empty.
+func (client *SchemaRegistryClient) checkCompatibility(subject, schema, version string, schemaType SchemaType,
+	verbose bool, references []Reference) (*isCompatibleResponse, error) {
 	if references == nil {
 		references = make([]Reference, 0)
 	}
@@ -582,58 +2527,194 @@ func (client *SchemaRegistryClient) IsSchemaCompatible(subject, schema, version
 	schemaReq := schemaRequest{Schema: schema, SchemaType: schemaType.String(), References: references}
 	schemaReqBytes, err := json.Marshal(schemaReq)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	payload := bytes.NewBuffer(schemaReqBytes)
 
-	url := fmt.Sprintf("/compatibility/subjects/%s/versions/%s", subject, version)
-	resp, err := client.httpRequest("POST", url, payload)
+	uri := fmt.Sprintf("/compatibility/subjects/%s/versions", url.PathEscape(subject))
+	if version != "" {
+		uri += "/" + version
+	}
+	if verbose {
+		uri += "?verbose=true"
+	}
+	resp, err := client.httpRequest("POST", uri, payload)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	compatibilityResponse := new(isCompatibleResponse)
 	err = json.Unmarshal(resp, compatibilityResponse)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	return compatibilityResponse.IsCompatible, nil
+	return compatibilityResponse, nil
 }
 
-// DeleteSubject deletes
+// DeleteSubject deletes the subject, evicting its cached versions once it's deleted
 func (client *SchemaRegistryClient) DeleteSubject(subject string, permanent bool) error {
-	uri := "/subjects/" + subject
+	uri := fmt.Sprintf(subjectBySubject, url.PathEscape(subject))
 	_, err := client.httpRequest("DELETE", uri, nil)
-	if err != nil || !permanent {
+	if err != nil {
 		return err
 	}
+	client.evictDeleted(subject, 0)
+	if !permanent {
+		return nil
+	}
 
 	uri += "?permanent=true"
 	_, err = client.httpRequest("DELETE", uri, nil)
 	return err
 }
 
-// DeleteSubjectByVersion deletes the version of the scheme
+// DeleteAllSubjects deletes every subject in Schema Registry, as many at once as the
+// semaphore weight allows, and returns the subjects it deleted, sorted by name. If
+// dryRun is true nothing is deleted, and the subjects that would be are returned.
+// If some subjects can't be deleted, the ones that were are returned along with a
+// *DeleteSubjectsError holding the failures.
+func (client *SchemaRegistryClient) DeleteAllSubjects(permanent bool, dryRun bool) ([]string, error) {
+	allSubjects, err := client.GetSubjects()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(allSubjects)
+	if dryRun {
+		return allSubjects, nil
+	}
+
+	errs := make(map[string]error)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for _, subject := range allSubjects {
+		wg.Add(1)
+		go func(subject string) {
+			defer wg.Done()
+			if err := client.DeleteSubject(subject, permanent); err != nil {
+				lock.Lock()
+				errs[subject] = err
+				lock.Unlock()
+			}
+		}(subject)
+	}
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return allSubjects, nil
+	}
+	deleted := make([]string, 0, len(allSubjects)-len(errs))
+	for _, subject := range allSubjects {
+		if _, failed := errs[subject]; !failed {
+			deleted = append(deleted, subject)
+		}
+	}
+	return deleted, &DeleteSubjectsError{Errors: errs}
+}
+
+// DeleteSubjectByVersion deletes the version of the scheme, evicting it from the caches once it's deleted
 func (client *SchemaRegistryClient) DeleteSubjectByVersion(subject string, version int, permanent bool) error {
-	uri := fmt.Sprintf(subjectByVersion, subject, strconv.Itoa(version))
+	uri := fmt.Sprintf(subjectByVersion, url.PathEscape(subject), strconv.Itoa(version))
 	_, err := client.httpRequest("DELETE", uri, nil)
-	if err != nil || !permanent {
+	if err != nil {
 		return err
 	}
+	client.evictDeleted(subject, version)
+	if !permanent {
+		return nil
+	}
 
 	uri += "?permanent=true"
 	_, err = client.httpRequest("DELETE", uri, nil)
 	return err
 }
 
+// DeleteSubjectVersionLatest deletes the latest version of the subject and returns its
+// number, as Schema Registry resolves it. If permanent is set to true, the version is
+// permanently deleted after the soft delete, using the number returned by the latter.
+func (client *SchemaRegistryClient) DeleteSubjectVersionLatest(subject string, permanent bool) (int, error) {
+	resp, err := client.httpRequest("DELETE", fmt.Sprintf(subjectByVersion, url.PathEscape(subject), "latest"), nil)
+	if err != nil {
+		return 0, err
+	}
+	var version int
+	if err := json.Unmarshal(resp, &version); err != nil {
+		return 0, err
+	}
+	client.evictDeleted(subject, version)
+	if !permanent {
+		return version, nil
+	}
+
+	// latest now resolves to the previous version, so the deleted one is given by its number
+	uri := fmt.Sprintf(subjectByVersion, url.PathEscape(subject), strconv.Itoa(version)) + "?permanent=true"
+	if _, err := client.httpRequest("DELETE", uri, nil); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// DeleteSchemaByID deletes every subject version the schema is registered with.
+// Failures don't stop the other versions from being deleted, and are returned
+// together as *DeleteSchemaError.
+func (client *SchemaRegistryClient) DeleteSchemaByID(schemaID int, permanent bool) error {
+	subjectVersions, err := client.GetSubjectVersionsById(schemaID)
+	if err != nil {
+		return err
+	}
+
+	errs := make(map[string]error)
+	for _, pair := range subjectVersions {
+		if err := client.DeleteSubjectByVersion(pair.Subject, pair.Version, permanent); err != nil {
+			errs[fmt.Sprintf("%s/%d", pair.Subject, pair.Version)] = err
+		}
+	}
+	if len(errs) > 0 {
+		return &DeleteSchemaError{SchemaID: schemaID, Errors: errs}
+	}
+	return nil
+}
+
+// PurgeSubjectVersion permanently deletes the version of the subject,
+// performing the soft delete followed by the permanent delete. Versions
+// that were already soft deleted or permanently deleted are not treated
+// as failures. Failures are returned as *PurgeError, which tells whether
+// the soft delete succeeded before the permanent delete failed.
+func (client *SchemaRegistryClient) PurgeSubjectVersion(subject string, version int) error {
+	uri := fmt.Sprintf(subjectByVersion, url.PathEscape(subject), strconv.Itoa(version))
+	_, err := client.httpRequest("DELETE", uri, nil)
+	if err != nil {
+		if errors.Is(err, ErrSubjectNotFound) || errors.Is(err, ErrVersionNotFound) {
+			// Already permanently deleted
+			client.evictDeleted(subject, version)
+			return nil
+		}
+		if !errors.Is(err, ErrVersionSoftDeleted) {
+			return &PurgeError{SoftDeleted: false, Err: err}
+		}
+	}
+	client.evictDeleted(subject, version)
+
+	_, err = client.httpRequest("DELETE", uri+"?permanent=true", nil)
+	if err != nil {
+		if errors.Is(err, ErrSubjectNotFound) || errors.Is(err, ErrVersionNotFound) {
+			// Already permanently deleted
+			return nil
+		}
+		return &PurgeError{SoftDeleted: true, Err: err}
+	}
+	return nil
+}
+
 // SetCredentials allows users to set credentials to be
 // used with Schema Registry, for scenarios when Schema
 // Registry has authentication enabled.
 func (client *SchemaRegistryClient) SetCredentials(username string, password string) {
 	if len(username) > 0 && len(password) > 0 {
 		credentials := credentials{username: username, password: password, bearerToken: ""}
+		client.credentialsLock.Lock()
 		client.credentials = &credentials
+		client.credentialsLock.Unlock()
 	}
 }
 
@@ -643,13 +2724,21 @@ func (client *SchemaRegistryClient) SetCredentials(username string, password str
 func (client *SchemaRegistryClient) SetBearerToken(token string) {
 	if len(token) > 0 {
 		credentials := credentials{username: "", password: "", bearerToken: token}
+		client.credentialsLock.Lock()
 		client.credentials = &credentials
+		client.credentialsLock.Unlock()
 	}
 }
 
 // SetTimeout allows the client to be reconfigured about
 // how much time internal HTTP requests will take until
 // they timeout. FYI, It defaults to five seconds.
+//
+// The methods taking a context are bounded by its deadline
+// instead, when it has one, so a slow call can be given more
+// time without raising the timeout of every other call.
+// Without a deadline, the client timeout applies and the
+// context can still cancel the call before it's reached.
 func (client *SchemaRegistryClient) SetTimeout(timeout time.Duration) {
 	client.httpClient.Timeout = timeout
 }
@@ -680,19 +2769,67 @@ func (client *SchemaRegistryClient) CodecJsonEnabled(value bool) {
 	client.codecAsFullJson = value
 }
 
-func (client *SchemaRegistryClient) getVersion(subject string, version string) (*Schema, error) {
+// getCreatedSchema fetches a schema that has just been registered,
+// retrying on 40403 as configured through WithCreateGetRetry.
+func (client *SchemaRegistryClient) getCreatedSchema(ctx context.Context, schemaID int) (*Schema, error) {
+	var schema *Schema
+	err := retryOn(ctx, client.clock, client.createGetAttempts, func() error {
+		var err error
+		schema, err = client.GetSchemaWithContext(ctx, schemaID)
+		return err
+	}, isStaleRead, client.staleReadDelay)
+	return schema, err
+}
+
+// staleReadDelay returns the delay before the given attempt of a read done right after a write.
+func (client *SchemaRegistryClient) staleReadDelay(attempt int) time.Duration {
+	if !client.createGetBackoff {
+		return client.createGetDelay
+	}
+	return client.createGetDelay << uint(attempt-1)
+}
+
+// isStaleRead reports whether the error is a 40403 returned by Schema Registry, which
+// right after a write means the request hit a backend that hasn't caught up with it.
+func isStaleRead(err error) bool {
+	return registryErrorCode(err) == 40403
+}
+
+// retryOn calls fn until it succeeds, it fails with an error retryable doesn't match, or it
+// has been called attempts times. The delay before every retry is returned by backoff, which
+// is given the number of the retry, starting from 1, and waited for with the clock. The
+// last error of fn is returned, or the error of the context if it's done while waiting.
+func retryOn(ctx context.Context, clock Clock, attempts int, fn func() error, retryable func(err error) bool,
+	backoff func(attempt int) time.Duration) error {
+	err := fn()
+	for attempt := 1; attempt < attempts && err != nil && retryable(err); attempt++ {
+		select {
+		case <-clock.After(backoff(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		err = fn()
+	}
+	return err
+}
+
+func (client *SchemaRegistryClient) getVersion(ctx context.Context, subject string, version string) (*Schema, error) {
 
 	if client.getCachingEnabled() {
-		cacheKey := cacheKey(subject, version)
-		client.subjectSchemaCacheLock.RLock()
-		cachedResult := client.subjectSchemaCache[cacheKey]
-		client.subjectSchemaCacheLock.RUnlock()
-		if cachedResult != nil {
+		if cachedResult := client.getCachedSchemaBySubject(cacheKey(subject, version)); cachedResult != nil {
+			atomic.AddUint64(&client.subjectCacheHits, 1)
 			return cachedResult, nil
 		}
+		atomic.AddUint64(&client.subjectCacheMisses, 1)
 	}
 
-	resp, err := client.httpRequest("GET", fmt.Sprintf(subjectByVersion, url.QueryEscape(subject), version), nil)
+	return client.dedupe(ctx, "subject-"+cacheKey(subject, version), func(ctx context.Context) (*Schema, error) {
+		return client.fetchVersion(ctx, subject, version, client.getCodecCreationEnabled())
+	})
+}
+
+func (client *SchemaRegistryClient) fetchVersion(ctx context.Context, subject string, version string, createCodec bool) (*Schema, error) {
+	resp, err := client.httpRequestWithContext(ctx, "GET", fmt.Sprintf(subjectByVersion, url.PathEscape(subject), version), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -703,7 +2840,7 @@ func (client *SchemaRegistryClient) getVersion(subject string, version string) (
 		return nil, err
 	}
 	var codec *goavro.Codec
-	if client.getCodecCreationEnabled() {
+	if createCodec {
 		codec, err = client.getCodecForSchema(schemaResp.Schema)
 		if err != nil {
 			return nil, err
@@ -715,62 +2852,480 @@ func (client *SchemaRegistryClient) getVersion(subject string, version string) (
 		schemaType: schemaResp.SchemaType,
 		version:    schemaResp.Version,
 		references: schemaResp.References,
+		metadata:   schemaResp.Metadata,
+		ruleSet:    schemaResp.RuleSet,
 		codec:      codec,
 	}
 
-	if client.getCachingEnabled() {
+	// Schemas fetched without the codec the cache is expected to hold, as GetLatestSchemaMetadata
+	// does, are not cached, or GetLatestSchema and GetSchemaByVersion would return them
+	if client.getCachingEnabled() && createCodec == client.getCodecCreationEnabled() {
 
 		// Update the subject-2-schema cache
-		cacheKey := cacheKey(subject, version)
-		client.subjectSchemaCacheLock.Lock()
-		client.subjectSchemaCache[cacheKey] = schema
-		client.subjectSchemaCacheLock.Unlock()
+		client.cacheSchemaBySubject(cacheKey(subject, version), schema)
+
+		// The latest version is also cached under its number, which never changes
+		if version == "latest" && schema.version > 0 {
+			client.cacheSchemaBySubject(cacheKey(subject, strconv.Itoa(schema.version)), schema)
+		}
 
 		// Update the id-2-schema cache
-		client.idSchemaCacheLock.Lock()
-		client.idSchemaCache[schema.id] = schema
-		client.idSchemaCacheLock.Unlock()
+		client.cacheSchemaByID(schema.id, schema)
 
 	}
 
 	return schema, nil
 }
 
+// dedupe makes concurrent calls fetching the same key share
+// a single in-flight call, unless singleflight is disabled.
+// The shared call runs detached from the context of the call
+// that started it, bound by the client timeout only, so that
+// cancelling one call doesn't fail the others waiting on it.
+func (client *SchemaRegistryClient) dedupe(ctx context.Context, key string, fetch func(ctx context.Context) (*Schema, error)) (*Schema, error) {
+	if !client.singleflightEnabled {
+		return fetch(ctx)
+	}
+
+	results := client.requestGroup.DoChan(key, func() (interface{}, error) {
+		return fetch(detachedContext{ctx})
+	})
+	select {
+	case result := <-results:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*Schema), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// detachedContext keeps the values of its parent context, such as the
+// request ID, but is never done, neither when the parent is cancelled
+// nor when its deadline is reached.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
+
+func (ctx detachedContext) Value(key interface{}) interface{} { return ctx.parent.Value(key) }
+
 func (client *SchemaRegistryClient) httpRequest(method, uri string, payload io.Reader) ([]byte, error) {
+	return client.httpRequestWithContext(context.Background(), method, uri, payload)
+}
+
+func (client *SchemaRegistryClient) httpRequestWithContext(ctx context.Context, method, uri string, payload io.Reader) ([]byte, error) {
+	start := time.Now()
+	ctx, requestID := client.withRequestID(ctx)
+	resp, statusCode, err := client.retryHTTPRequest(ctx, method, uri, payload)
+	client.observeRequest(method, uri, requestID, statusCode, start, err)
+	return resp, err
+}
+
+// requestIDKey is the key of the request ID in the context of a request.
+type requestIDKey struct{}
+
+// withRequestID generates the ID of the request with the configured generator, if any,
+// and returns it along with a context holding it, so it's sent on every attempt.
+func (client *SchemaRegistryClient) withRequestID(ctx context.Context) (context.Context, string) {
+	if client.requestIDGenerator == nil {
+		return ctx, ""
+	}
+	requestID := client.requestIDGenerator()
+	return context.WithValue(ctx, requestIDKey{}, requestID), requestID
+}
+
+// observeRequest notifies the configured request observer, if any, that the request completed.
+func (client *SchemaRegistryClient) observeRequest(method, uri, requestID string, statusCode int, start time.Time, err error) {
+	if client.requestObserver != nil {
+		client.requestObserver(RequestInfo{
+			Method:     method,
+			URI:        uri,
+			RequestID:  requestID,
+			StatusCode: statusCode,
+			Duration:   time.Since(start),
+			Err:        err,
+		})
+	}
+}
+
+// retryHTTPRequest sends the request, retrying it if enabled for its method,
+// and returns the status code of the last attempt along with its response.
+func (client *SchemaRegistryClient) retryHTTPRequest(ctx context.Context, method, uri string, payload io.Reader) ([]byte, int, error) {
+	if client.maxRetries <= 0 || !client.isRetryable(method) {
+		return client.doHTTPRequest(ctx, method, uri, payload)
+	}
+
+	// The payload has to be replayed on every attempt
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = ioutil.ReadAll(payload)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var lastErr error
+	var lastStatusCode int
+	for attempt := 0; attempt <= client.maxRetries; attempt++ {
+		if attempt > 0 {
+			delay, ok := retryAfter(client.clock, lastErr)
+			if !ok {
+				delay = client.retryDelay(attempt)
+			}
+			select {
+			case <-ctx.Done():
+				return nil, lastStatusCode, &RetryError{Attempts: attempt, Err: ctx.Err()}
+			case <-client.clock.After(delay):
+			}
+		}
+
+		var attemptPayload io.Reader
+		if body != nil {
+			attemptPayload = bytes.NewReader(body)
+		}
+		resp, statusCode, err := client.doHTTPRequest(ctx, method, uri, attemptPayload)
+		if err == nil {
+			return resp, statusCode, nil
+		}
+
+		// 4xx responses are not transient, so there is no point in retrying them,
+		// except when Schema Registry is throttling the requests
+		if statusCode != 0 && statusCode < 500 && statusCode != http.StatusTooManyRequests {
+			return nil, statusCode, err
+		}
+		lastErr, lastStatusCode = err, statusCode
+	}
+
+	return nil, lastStatusCode, &RetryError{Attempts: client.maxRetries + 1, Err: lastErr}
+}
 
-	url := fmt.Sprintf("%s%s", client.schemaRegistryURL, uri)
-	req, err := http.NewRequest(method, url, payload)
+func (client *SchemaRegistryClient) doHTTPRequest(ctx context.Context, method, uri string, payload io.Reader) ([]byte, int, error) {
+	var body []byte
+	statusCode, err := client.sendHTTPRequest(ctx, method, uri, payload, func(respBody io.Reader) error {
+		var err error
+		body, err = client.readResponseBody(respBody)
+		return err
+	})
+	return body, statusCode, err
+}
+
+// readResponseBody reads the body of the response, failing with
+// ErrResponseTooLarge if it exceeds the maximum response body size.
+func (client *SchemaRegistryClient) readResponseBody(respBody io.Reader) ([]byte, error) {
+	if client.maxResponseBodySize <= 0 {
+		return ioutil.ReadAll(respBody)
+	}
+
+	// Reading one more byte than allowed tells a body of the maximum size from a larger one
+	body, err := ioutil.ReadAll(io.LimitReader(respBody, client.maxResponseBodySize+1))
 	if err != nil {
 		return nil, err
 	}
-	if client.credentials != nil {
-		if len(client.credentials.username) > 0 && len(client.credentials.password) > 0 {
-			req.SetBasicAuth(client.credentials.username, client.credentials.password)
-		} else if len(client.credentials.bearerToken) > 0 {
-			if strings.Contains(strings.ToLower(uri), "confluent.cloud") {
-				req.Header.Add("Authorization", "Basic "+client.credentials.bearerToken)
+	if int64(len(body)) > client.maxResponseBodySize {
+		return nil, fmt.Errorf("%w: the limit is %d bytes", ErrResponseTooLarge, client.maxResponseBodySize)
+	}
+	return body, nil
+}
+
+// streamHTTPRequest sends the request without retrying it, as the response body
+// is handed over to handleBody as it's read rather than being read upfront.
+func (client *SchemaRegistryClient) streamHTTPRequest(method, uri string, handleBody func(body io.Reader) error) error {
+	start := time.Now()
+	ctx, requestID := client.withRequestID(context.Background())
+	statusCode, err := client.sendHTTPRequest(ctx, method, uri, nil, handleBody)
+	client.observeRequest(method, uri, requestID, statusCode, start, err)
+	return err
+}
+
+// sendHTTPRequest sends the request and hands over the body of successful responses to handleBody.
+func (client *SchemaRegistryClient) sendHTTPRequest(ctx context.Context, method, uri string, payload io.Reader,
+	handleBody func(body io.Reader) error) (int, error) {
+
+	compressed := false
+	if client.compression && payload != nil {
+		var err error
+		if payload, compressed, err = compressPayload(payload); err != nil {
+			return 0, err
+		}
+	}
+
+	// The registry may be mounted under a path, which is kept with or without a trailing slash
+	url := strings.TrimSuffix(client.schemaRegistryURL, "/") + uri
+	req, err := http.NewRequestWithContext(ctx, method, url, payload)
+	if err != nil {
+		return 0, err
+	}
+	if client.tokenSource != nil {
+		token, err := client.tokenSource.Token()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get a token from the token source: %w", err)
+		}
+		req.Header.Add("Authorization", "Bearer "+token)
+	} else if credentials := client.getCredentials(); credentials != nil {
+		if len(credentials.username) > 0 && len(credentials.password) > 0 {
+			req.SetBasicAuth(credentials.username, credentials.password)
+		} else if len(credentials.bearerToken) > 0 {
+			// Confluent Cloud expects its API keys with the Basic scheme
+			if strings.Contains(strings.ToLower(req.URL.Hostname()), "confluent.cloud") {
+				req.Header.Add("Authorization", "Basic "+credentials.bearerToken)
 			} else {
-				req.Header.Add("Authorization", "Bearer "+client.credentials.bearerToken)
+				req.Header.Add("Authorization", "Bearer "+credentials.bearerToken)
 			}
 		}
 	}
+	if proxyCredentials := client.proxyCredentials; proxyCredentials != nil {
+		basicAuth := base64.StdEncoding.EncodeToString([]byte(proxyCredentials.username + ":" + proxyCredentials.password))
+		req.Header.Set("Proxy-Authorization", "Basic "+basicAuth)
+	}
 	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", client.userAgent)
+	if client.compression {
+		// Setting it explicitly stops the transport from decompressing responses
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for key, values := range client.headers {
+		req.Header[key] = values
+	}
+	if requestID, ok := ctx.Value(requestIDKey{}).(string); ok {
+		req.Header.Set(requestIDHeader, requestID)
+	}
 
-	client.sem.Acquire(context.Background(), 1)
+	if err := client.acquireSemaphore(ctx); err != nil {
+		return 0, err
+	}
 	defer client.sem.Release(1)
-	resp, err := client.httpClient.Do(req)
+	start := time.Now()
+	resp, err := client.httpClientFor(ctx).Do(req)
 	if err != nil {
-		return nil, err
+		client.logRequest(req, 0, time.Since(start), err)
+		return 0, err
 	}
+	client.logRequest(req, resp.StatusCode, time.Since(start), nil)
 
 	if resp != nil {
 		defer resp.Body.Close()
 	}
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gzipReader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decompress the response: %w", err)
+		}
+		defer gzipReader.Close()
+		resp.Body = gzipReader
+	}
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return nil, createError(resp)
+		if client.maxResponseBodySize > 0 {
+			resp.Body = ioutil.NopCloser(io.LimitReader(resp.Body, client.maxResponseBodySize))
+		}
+		return resp.StatusCode, createError(resp)
+	}
+
+	return resp.StatusCode, handleBody(resp.Body)
+}
+
+// compressPayload gzips the payload if it's at least compressionThreshold bytes,
+// and reports whether it did. Smaller payloads are returned as they are.
+func compressPayload(payload io.Reader) (io.Reader, bool, error) {
+	body, err := ioutil.ReadAll(payload)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(body) < compressionThreshold {
+		return bytes.NewReader(body), false, nil
+	}
+
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	if _, err := gzipWriter.Write(body); err != nil {
+		return nil, false, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, false, err
+	}
+	return &compressed, true, nil
+}
+
+// httpClientFor returns the HTTP client to send the request with. When the context has
+// a deadline, it bounds the request instead of the timeout of the HTTP client.
+func (client *SchemaRegistryClient) httpClientFor(ctx context.Context) *http.Client {
+	if _, ok := ctx.Deadline(); !ok || client.httpClient.Timeout == 0 {
+		return client.httpClient
+	}
+	httpClient := *client.httpClient
+	httpClient.Timeout = 0
+	return &httpClient
+}
+
+// acquireSemaphore waits for the semaphore no longer than the timeout of the
+// HTTP client, so requests stuck in Schema Registry can't starve other callers.
+// When the context has a deadline, it's used instead of the timeout.
+func (client *SchemaRegistryClient) acquireSemaphore(ctx context.Context) error {
+	_, hasDeadline := ctx.Deadline()
+	if timeout := client.httpClient.Timeout; timeout > 0 && !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := client.sem.Acquire(ctx, 1); err != nil {
+		return fmt.Errorf("timed out waiting for one of the concurrent requests to complete: %w", err)
+	}
+	return nil
+}
+
+// logRequest traces the request with the configured logger, if any,
+// making sure the credentials sent along with it are not logged.
+func (client *SchemaRegistryClient) logRequest(req *http.Request, statusCode int, duration time.Duration, err error) {
+	if client.logger == nil {
+		return
+	}
+
+	headers := req.Header.Clone()
+	for _, header := range []string{"Authorization", "Proxy-Authorization"} {
+		if headers.Get(header) != "" {
+			headers.Set(header, "[REDACTED]")
+		}
+	}
+	if err != nil {
+		client.logger.Debugf("srclient: %s %s failed after %s: %v (headers: %v)", req.Method, req.URL.Redacted(), duration, err, headers)
+		return
+	}
+	client.logger.Debugf("srclient: %s %s returned %d in %s (headers: %v)", req.Method, req.URL.Redacted(), statusCode, duration, headers)
+}
+
+// isRetryable reports whether requests with the given method can be retried.
+func (client *SchemaRegistryClient) isRetryable(method string) bool {
+	return method == http.MethodGet || (method == http.MethodPost && client.retryOnPost)
+}
+
+// retryAfter returns the delay Schema Registry asked to wait before retrying
+// with the Retry-After header of the error response, either in seconds or as a date.
+func retryAfter(clock Clock, err error) (time.Duration, bool) {
+	var registryErr Error
+	if !errors.As(err, &registryErr) {
+		return 0, false
+	}
+	header := registryErr.RetryAfter
+	if header == "" {
+		return 0, false
+	}
+	if seconds, parseErr := strconv.Atoi(header); parseErr == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, parseErr := http.ParseTime(header); parseErr == nil {
+		delay := date.Sub(clock.Now())
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// retryDelay returns the exponential backoff delay for the given attempt,
+// with jitter applied so concurrent clients don't retry in lockstep.
+func (client *SchemaRegistryClient) retryDelay(attempt int) time.Duration {
+	delay := client.retryBaseDelay << uint(attempt-1)
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+func (client *SchemaRegistryClient) getCachedSchemaByID(schemaID int) *Schema {
+	if client.maxCacheEntries <= 0 {
+		client.idSchemaCacheLock.RLock()
+		defer client.idSchemaCacheLock.RUnlock()
+		return client.unexpired(client.idSchemaCache[schemaID])
+	}
+
+	// Hits reorder the LRU list, so they need the write lock
+	client.idSchemaCacheLock.Lock()
+	defer client.idSchemaCacheLock.Unlock()
+	cached := client.idSchemaCache[schemaID]
+	if cached == nil {
+		return nil
+	}
+	client.idSchemaLRU.MoveToFront(cached.element)
+	return client.unexpired(cached)
+}
+
+func (client *SchemaRegistryClient) cacheSchemaByID(schemaID int, schema *Schema) {
+	client.idSchemaCacheLock.Lock()
+	defer client.idSchemaCacheLock.Unlock()
+	if cached, ok := client.idSchemaCache[schemaID]; ok {
+		cached.schema, cached.cachedAt = schema, client.clock.Now()
+		client.idSchemaLRU.MoveToFront(cached.element)
+		return
+	}
+	element := client.idSchemaLRU.PushFront(schemaID)
+	client.idSchemaCache[schemaID] = &cachedSchema{schema: schema, cachedAt: client.clock.Now(), element: element}
+	if client.maxCacheEntries > 0 && client.idSchemaLRU.Len() > client.maxCacheEntries {
+		oldest := client.idSchemaLRU.Remove(client.idSchemaLRU.Back())
+		delete(client.idSchemaCache, oldest.(int))
+	}
+}
+
+func (client *SchemaRegistryClient) getCachedSchemaBySubject(cacheKey string) *Schema {
+	if client.maxCacheEntries <= 0 {
+		client.subjectSchemaCacheLock.RLock()
+		defer client.subjectSchemaCacheLock.RUnlock()
+		return client.unexpired(client.subjectSchemaCache[cacheKey])
+	}
+
+	// Hits reorder the LRU list, so they need the write lock
+	client.subjectSchemaCacheLock.Lock()
+	defer client.subjectSchemaCacheLock.Unlock()
+	cached := client.subjectSchemaCache[cacheKey]
+	if cached == nil {
+		return nil
+	}
+	client.subjectSchemaLRU.MoveToFront(cached.element)
+	return client.unexpired(cached)
+}
+
+func (client *SchemaRegistryClient) cacheSchemaBySubject(cacheKey string, schema *Schema) {
+	client.subjectSchemaCacheLock.Lock()
+	defer client.subjectSchemaCacheLock.Unlock()
+	if cached, ok := client.subjectSchemaCache[cacheKey]; ok {
+		cached.schema, cached.cachedAt = schema, client.clock.Now()
+		client.subjectSchemaLRU.MoveToFront(cached.element)
+		return
+	}
+	element := client.subjectSchemaLRU.PushFront(cacheKey)
+	client.subjectSchemaCache[cacheKey] = &cachedSchema{schema: schema, cachedAt: client.clock.Now(), element: element}
+	if client.maxCacheEntries > 0 && client.subjectSchemaLRU.Len() > client.maxCacheEntries {
+		oldest := client.subjectSchemaLRU.Remove(client.subjectSchemaLRU.Back())
+		delete(client.subjectSchemaCache, oldest.(string))
 	}
+}
+
+// unexpired returns the cached schema, or nil if it's missing or older than the cache TTL.
+func (client *SchemaRegistryClient) unexpired(cached *cachedSchema) *Schema {
+	if cached == nil {
+		return nil
+	}
+	if client.cacheTTL > 0 && client.clock.Now().Sub(cached.cachedAt) > client.cacheTTL {
+		return nil
+	}
+	return cached.schema
+}
 
-	return ioutil.ReadAll(resp.Body)
+func (client *SchemaRegistryClient) getCredentials() *credentials {
+	client.credentialsLock.RLock()
+	defer client.credentialsLock.RUnlock()
+	return client.credentials
 }
 
 func (client *SchemaRegistryClient) getCachingEnabled() bool {
@@ -843,53 +3398,229 @@ func (schema *Schema) References() []Reference {
 	return schema.references
 }
 
+// schemaJSON is the JSON representation of a Schema,
+// without the codec and json schema compiled from it.
+type schemaJSON struct {
+	ID         int         `json:"id"`
+	Schema     string      `json:"schema"`
+	SchemaType *SchemaType `json:"schemaType,omitempty"`
+	Version    int         `json:"version"`
+	References []Reference `json:"references,omitempty"`
+	Metadata   *Metadata   `json:"metadata,omitempty"`
+	RuleSet    *RuleSet    `json:"ruleSet,omitempty"`
+}
+
+// MarshalJSON encodes the schema with all its information, which allows exporting it.
+// The codec and json schema are left out, and are initialized again when needed.
+func (schema *Schema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(schemaJSON{
+		ID:         schema.id,
+		Schema:     schema.schema,
+		SchemaType: schema.schemaType,
+		Version:    schema.version,
+		References: schema.references,
+		Metadata:   schema.metadata,
+		RuleSet:    schema.ruleSet,
+	})
+}
+
+// UnmarshalJSON decodes a schema encoded with MarshalJSON.
+func (schema *Schema) UnmarshalJSON(data []byte) error {
+	var decoded schemaJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*schema = Schema{
+		id:         decoded.ID,
+		schema:     decoded.Schema,
+		schemaType: decoded.SchemaType,
+		version:    decoded.Version,
+		references: decoded.References,
+		metadata:   decoded.Metadata,
+		ruleSet:    decoded.RuleSet,
+	}
+	return nil
+}
+
+// Metadata ensures access to Metadata
+// Will return nil if the registry didn't return any
+func (schema *Schema) Metadata() *Metadata {
+	return schema.metadata
+}
+
+// RuleSet ensures access to RuleSet
+// Will return nil if the registry didn't return any
+func (schema *Schema) RuleSet() *RuleSet {
+	return schema.ruleSet
+}
+
 // Codec ensures access to Codec
 // Will try to initialize a new one if it hasn't been initialized before
 // Will return nil if it can't initialize a codec from the schema
 func (schema *Schema) Codec() *goavro.Codec {
+	codec, _ := schema.CodecWithError()
+	return codec
+}
+
+// CodecWithError works like Codec, but returns the
+// error when the codec can't be initialized from the schema
+func (schema *Schema) CodecWithError() (*goavro.Codec, error) {
+	schema.lazyLock.Lock()
+	defer schema.lazyLock.Unlock()
 	if schema.codec == nil {
 		codec, err := goavro.NewCodec(schema.Schema())
-		if err == nil {
-			schema.codec = codec
+		if err != nil {
+			return nil, err
 		}
+		schema.codec = codec
 	}
-	return schema.codec
+	return schema.codec, nil
 }
 
 // JsonSchema ensures access to JsonSchema
 // Will try to initialize a new one if it hasn't been initialized before
 // Will return nil if it can't initialize a json schema from the schema
 func (schema *Schema) JsonSchema() *jsonschema.Schema {
+	jsonSchema, _ := schema.JsonSchemaWithError()
+	return jsonSchema
+}
+
+// JsonSchemaWithError works like JsonSchema, but returns the
+// error when the json schema can't be compiled from the schema
+func (schema *Schema) JsonSchemaWithError() (*jsonschema.Schema, error) {
+	schema.lazyLock.Lock()
+	defer schema.lazyLock.Unlock()
 	if schema.jsonSchema == nil {
 		jsonSchema, err := jsonschema.CompileString("schema.json", schema.Schema())
-		if err == nil {
-			schema.jsonSchema = jsonSchema
+		if err != nil {
+			return nil, err
 		}
+		schema.jsonSchema = jsonSchema
+	}
+	return schema.jsonSchema, nil
+}
+
+// ValidateSchema checks that the schema is valid for its type without sending it to Schema
+// Registry, returning an error matching ErrInvalidSchema if it isn't. Avro schemas are
+// checked by creating a codec and Json schemas by compiling them, so schemas referencing
+// other schemas can't be validated. Protobuf schemas return ErrValidationNotSupported, as
+// srclient doesn't depend on a Protobuf parser, and are checked by protobuf.ValidateSchema.
+func ValidateSchema(schema string, schemaType SchemaType) error {
+	var err error
+	switch schemaType {
+	case Avro:
+		_, err = goavro.NewCodec(schema)
+	case Json:
+		_, err = jsonschema.CompileString("schema.json", schema)
+	case Protobuf:
+		return fmt.Errorf("%w: %s", ErrValidationNotSupported, schemaType)
+	default:
+		return fmt.Errorf("invalid schema type. valid values are Avro, Json, or Protobuf")
+	}
+	if err != nil {
+		return fmt.Errorf("%w for %s: %v", ErrInvalidSchema, schemaType, err)
 	}
-	return schema.jsonSchema
+	return nil
 }
 
 func cacheKey(subject string, version string) string {
 	return fmt.Sprintf("%s-%s", subject, version)
 }
 
+var (
+	// ErrSubjectNotFound matches errors returned by Schema Registry with the 40401 error code.
+	ErrSubjectNotFound = errors.New("subject not found")
+	// ErrVersionNotFound matches errors returned by Schema Registry with the 40402 error code.
+	ErrVersionNotFound = errors.New("version not found")
+	// ErrSchemaNotFound matches errors returned by Schema Registry with the 40403 error code.
+	ErrSchemaNotFound = errors.New("schema not found")
+	// ErrVersionSoftDeleted matches errors returned by Schema Registry with the 40406 error code.
+	ErrVersionSoftDeleted = errors.New("version was soft deleted")
+	// ErrCompatibilityNotConfigured matches errors returned by Schema Registry with the 40408 error code.
+	ErrCompatibilityNotConfigured = errors.New("subject does not have subject-level compatibility configured")
+	// ErrIncompatibleSchema matches errors returned by Schema Registry with the 409 error code.
+	ErrIncompatibleSchema = errors.New("schema is incompatible with an earlier schema")
+	// ErrResponseTooLarge is returned when a response exceeds the size set with WithMaxResponseBodySize.
+	ErrResponseTooLarge = errors.New("response body is too large")
+	// ErrInvalidSchema is returned by ValidateSchema when the schema isn't valid for its type.
+	ErrInvalidSchema = errors.New("invalid schema")
+	// ErrValidationNotSupported is returned by ValidateSchema for the schema types it can't check.
+	ErrValidationNotSupported = errors.New("schema validation is not supported")
+)
+
+// registryErrors maps the error codes returned by Schema Registry to their sentinel errors.
+var registryErrors = map[int]error{
+	40401: ErrSubjectNotFound,
+	40402: ErrVersionNotFound,
+	40403: ErrSchemaNotFound,
+	40406: ErrVersionSoftDeleted,
+	40408: ErrCompatibilityNotConfigured,
+	409:   ErrIncompatibleSchema,
+}
+
 // Error implements error, encodes HTTP errors from Schema Registry.
+// StatusCode and Body are always set, while Code and Message are
+// only set when the body is a JSON error returned by Schema Registry.
 type Error struct {
-	Code    int    `json:"error_code"`
-	Message string `json:"message"`
-	str     *bytes.Buffer
+	Code       int    `json:"error_code"`
+	Message    string `json:"message"`
+	StatusCode int    `json:"-"`
+	Body       string `json:"-"`
+	// RetryAfter is the Retry-After header of the response,
+	// telling how long to wait before retrying, if it's set
+	RetryAfter string `json:"-"`
+	// lookup is set on errors returned by LookupSchema, for
+	// which subjects not found also match ErrSchemaNotFound
+	lookup bool
 }
 
 func (e Error) Error() string {
-	return e.str.String()
+	if e.Code != 0 {
+		return e.Body
+	}
+	status := fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if len(e.Body) == 0 {
+		return status
+	}
+	return fmt.Sprintf("%s: %s", status, e.Body)
+}
+
+// Is allows matching errors returned by Schema Registry with the
+// sentinel errors for their code, e.g. errors.Is(err, ErrSubjectNotFound).
+func (e Error) Is(target error) bool {
+	if e.lookup && e.Code == 40401 && target == ErrSchemaNotFound {
+		return true
+	}
+	sentinel, ok := registryErrors[e.Code]
+	return ok && sentinel == target
+}
+
+// Unwrap returns the sentinel error for the code returned by Schema Registry,
+// e.g. ErrSubjectNotFound, or nil if there is none for the code.
+func (e Error) Unwrap() error {
+	return registryErrors[e.Code]
+}
+
+// HTTPStatusCode returns the status code of the response Schema Registry failed with.
+func (e Error) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+// registryErrorCode returns the error code of an Error returned by Schema Registry, or 0 otherwise.
+func registryErrorCode(err error) int {
+	var registryErr Error
+	if errors.As(err, &registryErr) {
+		return registryErr.Code
+	}
+	return 0
 }
 
 func createError(resp *http.Response) error {
-	err := Error{str: bytes.NewBuffer(make([]byte, 0))}
-	decoder := json.NewDecoder(io.TeeReader(resp.Body, err.str))
-	marshalErr := decoder.Decode(&err)
-	if marshalErr != nil {
-		return fmt.Errorf("%s", resp.Status)
+	body, _ := ioutil.ReadAll(resp.Body)
+	err := Error{StatusCode: resp.StatusCode, Body: string(body), RetryAfter: resp.Header.Get("Retry-After")}
+	if marshalErr := json.Unmarshal(body, &err); marshalErr != nil {
+		// Not a JSON error from Schema Registry, e.g. an HTML page from a proxy
+		err.Code, err.Message = 0, ""
 	}
 
 	return err
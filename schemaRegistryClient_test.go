@@ -1,13 +1,23 @@
 package srclient
 
 import (
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/linkedin/goavro/v2"
 	"github.com/santhosh-tekuri/jsonschema/v5"
@@ -29,6 +39,7 @@ func TestNewSchemaRegistryClient_SetsExpectedOptions(t *testing.T) {
 
 		expectedClient          *http.Client
 		expectedSemaphoreWeight int64
+		expectedNoSingleflight  bool
 	}{
 		"no options": {
 			registryUrl: "localhost:8080",
@@ -50,6 +61,14 @@ func TestNewSchemaRegistryClient_SetsExpectedOptions(t *testing.T) {
 			expectedClient:          &http.Client{Timeout: 32},
 			expectedSemaphoreWeight: defaultSemaphoreWeight,
 		},
+		"without singleflight": {
+			registryUrl: "local:8080",
+			options:     []Option{WithoutSingleflight()},
+
+			expectedClient:          &http.Client{Timeout: defaultTimeout},
+			expectedSemaphoreWeight: defaultSemaphoreWeight,
+			expectedNoSingleflight:  true,
+		},
 	}
 
 	for name, testData := range tests {
@@ -62,6 +81,7 @@ func TestNewSchemaRegistryClient_SetsExpectedOptions(t *testing.T) {
 			// Assert
 			assert.Equal(t, testData.registryUrl, result.schemaRegistryURL)
 			assert.Equal(t, testData.expectedClient, result.httpClient)
+			assert.Equal(t, !testData.expectedNoSingleflight, result.singleflightEnabled)
 
 			// We should be able to acquire the semaphore by the size we specified
 			assert.True(t, result.sem.TryAcquire(testData.expectedSemaphoreWeight))
@@ -163,6 +183,427 @@ func TestSchemaRegistryClient_CreateSchemaWithoutReferences(t *testing.T) {
 	}
 }
 
+func TestSchemaRegistryClient_CreateSchemaRetriesStaleRead(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		options       []Option
+		expectedCalls int
+		expectError   bool
+	}{
+		"no retry": {
+			expectedCalls: 1,
+			expectError:   true,
+		},
+		"retry until found": {
+			options:       []Option{WithCreateGetRetry(5, time.Millisecond)},
+			expectedCalls: 3,
+		},
+		"retries exhausted": {
+			options:       []Option{WithCreateGetRetry(2, time.Millisecond)},
+			expectedCalls: 2,
+			expectError:   true,
+		},
+		"retry with backoff until found": {
+			options:       []Option{WithStaleReadRetry(3, time.Millisecond)},
+			expectedCalls: 3,
+		},
+		"retries with backoff exhausted": {
+			options:       []Option{WithStaleReadRetry(2, time.Millisecond)},
+			expectedCalls: 2,
+			expectError:   true,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var getCalls int
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "test2", ID: 1})
+				switch req.URL.String() {
+				case "/subjects/test1/versions":
+					rw.Write(response)
+				case "/schemas/ids/1":
+					getCalls++
+					// Simulate a backend that hasn't caught up with the registration
+					if getCalls < 3 {
+						rw.WriteHeader(http.StatusNotFound)
+						rw.Write([]byte(`{"error_code":40403,"message":"Schema 1 not found"}`))
+						return
+					}
+					rw.Write(response)
+				default:
+					require.Fail(t, "unhandled request")
+				}
+			}))
+
+			srClient := NewSchemaRegistryClient(server.URL, testData.options...)
+			schema, err := srClient.CreateSchema("test1", "test2", Avro)
+
+			assert.Equal(t, testData.expectedCalls, getCalls)
+			if testData.expectError {
+				castedErr, ok := err.(Error)
+				assert.True(t, ok, "convert api error to Error struct")
+				assert.Equal(t, 40403, castedErr.Code)
+			} else if assert.NoError(t, err) {
+				assert.Equal(t, 1, schema.ID())
+			}
+		})
+	}
+}
+
+func TestRetryOn(t *testing.T) {
+	t.Parallel()
+	errStale := Error{Code: 40403}
+	errNotFound := Error{Code: 40401}
+	tests := map[string]struct {
+		errs          []error
+		attempts      int
+		expectedCalls int
+		expectedErr   error
+	}{
+		"succeeds first": {
+			errs:          []error{nil},
+			attempts:      3,
+			expectedCalls: 1,
+		},
+		"succeeds after stale reads": {
+			errs:          []error{errStale, errStale, nil},
+			attempts:      3,
+			expectedCalls: 3,
+		},
+		"attempts exhausted": {
+			errs:          []error{errStale, errStale, errStale},
+			attempts:      2,
+			expectedCalls: 2,
+			expectedErr:   errStale,
+		},
+		"not retryable": {
+			errs:          []error{errNotFound, nil},
+			attempts:      3,
+			expectedCalls: 1,
+			expectedErr:   errNotFound,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var calls int
+			var delays []int
+			err := retryOn(context.Background(), &fakeClock{}, testData.attempts, func() error {
+				calls++
+				return testData.errs[calls-1]
+			}, isStaleRead, func(attempt int) time.Duration {
+				delays = append(delays, attempt)
+				return 0
+			})
+
+			assert.Equal(t, testData.expectedErr, err)
+			assert.Equal(t, testData.expectedCalls, calls)
+			assert.Len(t, delays, calls-1)
+		})
+	}
+}
+
+func TestRetryOn_ReturnsWhenContextIsDone(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+
+	err := retryOn(ctx, realClock{}, 3, func() error {
+		calls++
+		cancel()
+		return Error{Code: 40403}
+	}, isStaleRead, func(int) time.Duration {
+		return time.Hour
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+}
+
+func TestSchemaRegistryClient_StaleReadDelay(t *testing.T) {
+	t.Parallel()
+	fixed := NewSchemaRegistryClient("http://localhost:8081", WithCreateGetRetry(4, 10*time.Millisecond))
+	backoff := NewSchemaRegistryClient("http://localhost:8081", WithStaleReadRetry(4, 10*time.Millisecond))
+
+	for attempt, expected := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond} {
+		assert.Equal(t, 10*time.Millisecond, fixed.staleReadDelay(attempt+1))
+		assert.Equal(t, expected, backoff.staleReadDelay(attempt+1))
+	}
+}
+
+func TestSchemaRegistryClient_RegisterSchemaWithUpdatedReferences(t *testing.T) {
+	t.Parallel()
+	var created bool
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/subjects/common/versions/2":
+			response, _ := json.Marshal(schemaResponse{Subject: "common", Version: 2, Schema: "common", ID: 2})
+			rw.Write(response)
+		case "/subjects/common/versions/1":
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"error_code":40402,"message":"Version 1 not found."}`))
+		case "/subjects/missing/versions/1":
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"error_code":40401,"message":"Subject 'missing' not found."}`))
+		case "/subjects/test1/versions":
+			created = true
+			response, _ := json.Marshal(schemaResponse{ID: 3})
+			rw.Write(response)
+		case "/schemas/ids/3":
+			response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "payload", ID: 3})
+			rw.Write(response)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+
+	{
+		// Test dangling references are listed and nothing is registered
+		deleted := Reference{Name: "common.proto", Subject: "common", Version: 1}
+		missing := Reference{Name: "missing.proto", Subject: "missing", Version: 1}
+		schema, err := srClient.RegisterSchemaWithUpdatedReferences("test1", "payload", Protobuf,
+			[]Reference{deleted, {Name: "common.proto", Subject: "common", Version: 2}, missing})
+
+		assert.Nil(t, schema)
+		var danglingErr *DanglingReferencesError
+		if assert.ErrorAs(t, err, &danglingErr) {
+			assert.Equal(t, []Reference{deleted, missing}, danglingErr.References)
+		}
+		assert.False(t, created)
+	}
+	{
+		// Test the schema is registered when all references exist
+		schema, err := srClient.RegisterSchemaWithUpdatedReferences("test1", "payload", Protobuf,
+			[]Reference{{Name: "common.proto", Subject: "common", Version: 2}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, schema.ID())
+		assert.True(t, created)
+	}
+}
+
+func TestSchemaRegistryClient_CreateSchemaWithID(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 3, Schema: "test2", ID: 12})
+		switch req.URL.String() {
+		case "/subjects/test1/versions":
+			// Test payload
+			assert.Equal(t, `{"schema":"test2","schemaType":"PROTOBUF","id":12,"version":3}`, bodyToString(req.Body))
+			rw.Write(response)
+		case "/schemas/ids/12":
+			rw.Write(response)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	schema, err := srClient.CreateSchemaWithID("test1", "test2", Protobuf, 12, 3)
+
+	// Test response
+	assert.NoError(t, err)
+	assert.Equal(t, 12, schema.ID())
+	assert.Equal(t, 3, schema.Version())
+}
+
+func TestSchemaRegistryClient_ImportSchema(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/subjects/test1/versions", "/subjects/test2/versions":
+			assert.Equal(t, `{"schema":"test2","schemaType":"PROTOBUF","id":12,"version":3}`, bodyToString(req.Body))
+			rw.WriteHeader(http.StatusUnprocessableEntity)
+			rw.Write([]byte(`{"error_code":42205,"message":"Overwrite new schema with id 12 is not permitted."}`))
+		case "/subjects/test1":
+			response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 3, Schema: "test2", ID: 12})
+			rw.Write(response)
+		case "/subjects/test2":
+			response, _ := json.Marshal(schemaResponse{Subject: "test2", Version: 1, Schema: "test2", ID: 7})
+			rw.Write(response)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+
+	{
+		// Test the schema already imported with the same id and version is returned
+		schema, err := srClient.ImportSchema("test1", "test2", Protobuf, 12, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, 12, schema.ID())
+		assert.Equal(t, 3, schema.Version())
+	}
+	{
+		// Test the error is returned when the schema is registered with another id
+		schema, err := srClient.ImportSchema("test2", "test2", Protobuf, 12, 3)
+		assert.Nil(t, schema)
+		var registryErr Error
+		assert.ErrorAs(t, err, &registryErr)
+		assert.Equal(t, 42205, registryErr.Code)
+	}
+}
+
+func TestSchemaRegistryClient_CreateSchemaWithConfig(t *testing.T) {
+	t.Parallel()
+	metadata := &Metadata{Properties: map[string]string{"owner": "team1"}}
+	ruleSet := &RuleSet{DomainRules: []Rule{{Name: "rule1", Kind: "CONDITION", Type: "CEL", Expr: "true"}}}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "test2", ID: 1, Metadata: metadata, RuleSet: ruleSet})
+		switch req.URL.String() {
+		case "/subjects/test1/versions":
+			// Test payload
+			assert.Equal(t, `{"schema":"test2","schemaType":"PROTOBUF",`+
+				`"metadata":{"properties":{"owner":"team1"}},`+
+				`"ruleSet":{"domainRules":[{"name":"rule1","kind":"CONDITION","type":"CEL","expr":"true"}]}}`, bodyToString(req.Body))
+			rw.Write(response)
+		case "/schemas/ids/1":
+			rw.Write(response)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	schema, err := srClient.CreateSchemaWithConfig("test1", RegisterSchemaRequest{
+		Schema:     "test2",
+		SchemaType: Protobuf,
+		Metadata:   metadata,
+		RuleSet:    ruleSet,
+	})
+
+	// Test response
+	assert.NoError(t, err)
+	assert.Equal(t, metadata, schema.Metadata())
+	assert.Equal(t, ruleSet, schema.RuleSet())
+}
+
+func TestSchemaRegistryClient_CreateSchemaWithCompatibility(t *testing.T) {
+	t.Parallel()
+	{
+		var requests []string
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			requests = append(requests, req.Method+" "+req.URL.String())
+			switch req.URL.String() {
+			case "/config/test1":
+				assert.Equal(t, `{"compatibility":"FULL_TRANSITIVE"}`, bodyToString(req.Body))
+				rw.Write([]byte(`{"compatibility":"FULL_TRANSITIVE"}`))
+			case "/subjects/test1/versions", "/schemas/ids/1":
+				response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "test2", ID: 1})
+				rw.Write(response)
+			default:
+				require.Fail(t, "unhandled request")
+			}
+		}))
+
+		srClient := CreateSchemaRegistryClient(server.URL)
+		schema, err := srClient.CreateSchemaWithCompatibility("test1", "test2", Protobuf, FullTransitive)
+
+		// Test the level is set before registering
+		assert.NoError(t, err)
+		assert.Equal(t, 1, schema.ID())
+		assert.Equal(t, []string{"PUT /config/test1", "POST /subjects/test1/versions", "GET /schemas/ids/1"}, requests)
+	}
+	{
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			switch req.URL.String() {
+			case "/config/test1":
+				rw.WriteHeader(http.StatusUnprocessableEntity)
+				rw.Write([]byte(`{"error_code":42203,"message":"Invalid compatibility level"}`))
+			default:
+				require.Fail(t, "unhandled request")
+			}
+		}))
+
+		srClient := CreateSchemaRegistryClient(server.URL)
+		schema, err := srClient.CreateSchemaWithCompatibility("test1", "test2", Protobuf, FullTransitive)
+
+		// Test nothing is registered when the level can't be set
+		assert.Nil(t, schema)
+		assert.Error(t, err)
+	}
+}
+
+func TestSchemaRegistryClient_CreateSchemaAndListVersions(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method + " " + req.URL.String() {
+		case "POST /subjects/test1/versions":
+			rw.Write([]byte(`{"id":3}`))
+		case "GET /schemas/ids/3":
+			response, _ := json.Marshal(schemaResponse{Schema: "test2", ID: 3})
+			rw.Write(response)
+		case "GET /subjects/test1/versions":
+			rw.Write([]byte(`[3,1,2]`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	schema, versions, err := srClient.CreateSchemaAndListVersions("test1", "test2", Protobuf)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, schema.ID())
+	assert.Equal(t, []int{1, 2, 3}, versions)
+}
+
+func TestSchemaRegistryClient_CreateSchemaIdempotent(t *testing.T) {
+	t.Parallel()
+	var registered int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method + " " + req.URL.String() {
+		case "POST /subjects/test1":
+			if atomic.LoadInt32(&registered) == 0 {
+				rw.WriteHeader(http.StatusNotFound)
+				rw.Write([]byte(`{"error_code":40401,"message":"Subject 'test1' not found."}`))
+				return
+			}
+			response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "test2", ID: 1})
+			rw.Write(response)
+		case "POST /subjects/test1/versions":
+			atomic.StoreInt32(&registered, 1)
+			rw.Write([]byte(`{"id":1}`))
+		case "GET /schemas/ids/1":
+			response, _ := json.Marshal(schemaResponse{Schema: "test2", ID: 1})
+			rw.Write(response)
+		case "POST /subjects/test2":
+			rw.WriteHeader(http.StatusUnauthorized)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+
+	{
+		schema, created, err := srClient.CreateSchemaIdempotent("test1", "test2", Protobuf)
+		assert.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, 1, schema.ID())
+	}
+	{
+		schema, created, err := srClient.CreateSchemaIdempotent("test1", "test2", Protobuf)
+		assert.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, 1, schema.ID())
+	}
+	{
+		// Errors other than not found are not hidden by trying to register the schema
+		_, created, err := srClient.CreateSchemaIdempotent("test2", "test2", Protobuf)
+		assert.Error(t, err)
+		assert.False(t, created)
+	}
+}
+
 func TestSchemaRegistryClient_LookupSchemaWithoutReferences(t *testing.T) {
 	t.Parallel()
 	var errorCode int
@@ -470,6 +911,85 @@ func TestSchemaRegistryClient_GetSchemaByVersionWithReferences(t *testing.T) {
 	}
 }
 
+func TestSchemaRegistryClient_GetSchemaByVersionWithMetadataAndRuleSet(t *testing.T) {
+	t.Parallel()
+	{
+		metadata := &Metadata{
+			Tags:       map[string][]string{"email": {"PII"}},
+			Properties: map[string]string{"owner": "team1"},
+		}
+		ruleSet := &RuleSet{
+			DomainRules: []Rule{{Name: "checkEmail", Kind: "CONDITION", Type: "CEL", Expr: "size(message.email) > 0"}},
+		}
+		server, _ := mockServerFromSubjectVersionPairWithSchemaResponse(t, "test1", "1", schemaResponse{
+			Subject:  "test1",
+			Version:  1,
+			Schema:   "payload",
+			ID:       1,
+			Metadata: metadata,
+			RuleSet:  ruleSet,
+		})
+
+		srClient := CreateSchemaRegistryClient(server.URL)
+		srClient.CodecCreationEnabled(false)
+		schema, err := srClient.GetSchemaByVersion("test1", 1)
+
+		// Test response
+		assert.NoError(t, err)
+		assert.Equal(t, metadata, schema.Metadata())
+		assert.Equal(t, ruleSet, schema.RuleSet())
+	}
+	{
+		server, _ := mockServerFromSubjectVersionPairWithSchemaResponse(t, "test1", "1", schemaResponse{
+			Subject: "test1",
+			Version: 1,
+			Schema:  "payload",
+			ID:      1,
+		})
+
+		srClient := CreateSchemaRegistryClient(server.URL)
+		srClient.CodecCreationEnabled(false)
+		schema, err := srClient.GetSchemaByVersion("test1", 1)
+
+		// Test response
+		assert.NoError(t, err)
+		assert.Nil(t, schema.Metadata())
+		assert.Nil(t, schema.RuleSet())
+	}
+}
+
+func TestSchemaRegistryClient_GetSchemaByVersionIncludingDeleted(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/subjects/test1/versions/1?deleted=true":
+			atomic.AddInt32(&calls, 1)
+			response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "payload", ID: 1})
+			rw.Write(response)
+		case "/subjects/test1/versions/1":
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"error_code":40402,"message":"Version 1 not found."}`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	srClient.CodecCreationEnabled(false)
+	schema, err := srClient.GetSchemaByVersionIncludingDeleted("test1", 1)
+
+	// Test response
+	assert.NoError(t, err)
+	assert.Equal(t, 1, schema.ID())
+	assert.Equal(t, 1, schema.Version())
+
+	// Test the soft deleted version isn't cached
+	_, err = srClient.GetSchemaByVersion("test1", 1)
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
 func TestSchemaRegistryClient_GetSchemaByVersionReturnsValueFromCache(t *testing.T) {
 	t.Parallel()
 	{
@@ -496,6 +1016,39 @@ func TestSchemaRegistryClient_GetSchemaByVersionReturnsValueFromCache(t *testing
 	}
 }
 
+func TestSchemaRegistryClient_GetLatestSchemaWithNotFoundAsNil(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/subjects/test1-value/versions/latest":
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"error_code":40401,"message":"Subject 'test1-value' not found."}`))
+		case "/subjects/test2-value/versions/latest":
+			rw.WriteHeader(http.StatusInternalServerError)
+			rw.Write([]byte(`{"error_code":50001,"message":"Error in the backend data store"}`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	{
+		srClient := CreateSchemaRegistryClient(server.URL)
+		schema, err := srClient.GetLatestSchema("test1-value")
+		assert.Nil(t, schema)
+		assert.ErrorIs(t, err, ErrSubjectNotFound)
+	}
+	{
+		srClient := NewSchemaRegistryClient(server.URL, WithNotFoundAsNil())
+		schema, err := srClient.GetLatestSchema("test1-value")
+		assert.Nil(t, schema)
+		assert.NoError(t, err)
+
+		schema, err = srClient.GetLatestSchema("test2-value")
+		assert.Nil(t, schema)
+		assert.Error(t, err)
+	}
+}
+
 func TestSchemaRegistryClient_GetLatestSchemaReturnsValueFromCache(t *testing.T) {
 	t.Parallel()
 	server, call := mockServerFromSubjectVersionPairWithSchemaResponse(t, "test1-value", "latest", schemaResponse{
@@ -520,28 +1073,593 @@ func TestSchemaRegistryClient_GetLatestSchemaReturnsValueFromCache(t *testing.T)
 	assert.Equal(t, schema1, schema2)
 }
 
-func TestSchemaRegistryClient_GetSchemaType(t *testing.T) {
+func TestSchemaRegistryClient_ConcurrentCallsShareASingleRequest(t *testing.T) {
 	t.Parallel()
-	{
-		expectedSchemaType := Json
-		server, call := mockServerFromSubjectVersionPairWithSchemaResponse(t, "test1-value", "latest", schemaResponse{
-			Subject:    "test1",
-			Version:    1,
-			Schema:     "payload",
-			SchemaType: &expectedSchemaType,
-			ID:         1,
-			References: nil,
-		})
+	const goroutines = 20
+	tests := map[string]func(srClient *SchemaRegistryClient) (*Schema, error){
+		"by id": func(srClient *SchemaRegistryClient) (*Schema, error) {
+			return srClient.GetSchema(1)
+		},
+		"by version": func(srClient *SchemaRegistryClient) (*Schema, error) {
+			return srClient.GetLatestSchema("test1")
+		},
+	}
 
-		srClient := CreateSchemaRegistryClient(server.URL)
-		schema, err := srClient.GetLatestSchema("test1-value")
+	for name, getSchema := range tests {
+		getSchema := getSchema
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var count int32
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				atomic.AddInt32(&count, 1)
+				// Keep the request in-flight while the other goroutines miss the cache
+				time.Sleep(50 * time.Millisecond)
+				response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "payload", ID: 1})
+				rw.Write(response)
+			}))
 
-		// Test response
-		assert.NoError(t, err)
-		assert.Equal(t, 1, *call)
-		assert.Equal(t, *schema.SchemaType(), expectedSchemaType)
+			srClient := CreateSchemaRegistryClient(server.URL)
+
+			var wg sync.WaitGroup
+			start := make(chan struct{})
+			for i := 0; i < goroutines; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					schema, err := getSchema(srClient)
+					assert.NoError(t, err)
+					assert.Equal(t, "payload", schema.Schema())
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+		})
 	}
-	{
+}
+
+func TestSchemaRegistryClient_MaxCacheEntriesEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+	var requestedIDs []string
+	var requestedIDsLock sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requestedIDsLock.Lock()
+		requestedIDs = append(requestedIDs, req.URL.String())
+		requestedIDsLock.Unlock()
+		response, _ := json.Marshal(schemaResponse{Schema: "payload"})
+		rw.Write(response)
+	}))
+
+	srClient := NewSchemaRegistryClient(server.URL, WithMaxCacheEntries(2))
+
+	for _, schemaID := range []int{1, 2, 1, 3, 1, 2} {
+		_, err := srClient.GetSchema(schemaID)
+		require.NoError(t, err)
+	}
+
+	// The hit on 1 makes 2 the least recently used schema, so caching 3 evicts it
+	assert.Equal(t, []string{"/schemas/ids/1", "/schemas/ids/2", "/schemas/ids/3", "/schemas/ids/2"}, requestedIDs)
+	assert.Len(t, srClient.idSchemaCache, 2)
+}
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (logger *recordingLogger) Debugf(format string, args ...interface{}) {
+	logger.lines = append(logger.lines, fmt.Sprintf(format, args...))
+}
+
+func TestSchemaRegistryClient_LogsRequestsWithoutCredentials(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte("[]"))
+	}))
+
+	logger := &recordingLogger{}
+	srClient := NewSchemaRegistryClient(server.URL, WithLogger(logger))
+	srClient.SetCredentials("username", "password")
+
+	_, err := srClient.GetSubjects()
+
+	require.NoError(t, err)
+	require.Len(t, logger.lines, 1)
+	assert.Contains(t, logger.lines[0], "GET "+server.URL+"/subjects returned 200")
+	assert.Contains(t, logger.lines[0], "[REDACTED]")
+	assert.NotContains(t, logger.lines[0], "Basic")
+}
+
+func TestSchemaRegistryClient_LogsRequestsWithoutURLPassword(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte("[]"))
+	}))
+
+	logger := &recordingLogger{}
+	srClient := NewSchemaRegistryClient(strings.Replace(server.URL, "http://", "http://username:password@", 1), WithLogger(logger))
+
+	_, err := srClient.GetSubjects()
+
+	require.NoError(t, err)
+	require.Len(t, logger.lines, 1)
+	assert.Contains(t, logger.lines[0], "username:xxxxx@")
+	assert.NotContains(t, logger.lines[0], "password")
+}
+
+func TestSchemaRegistryClient_SendsProxyBasicAuthWithBearerToken(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+		assert.Equal(t, "Basic cHJveHk6c2VjcmV0", req.Header.Get("Proxy-Authorization"))
+		rw.Write([]byte("[]"))
+	}))
+
+	logger := &recordingLogger{}
+	srClient := NewSchemaRegistryClient(server.URL,
+		WithBearerToken("token"), WithProxyBasicAuth("proxy", "secret"), WithLogger(logger))
+
+	_, err := srClient.GetSubjects()
+
+	require.NoError(t, err)
+	require.Len(t, logger.lines, 1)
+	assert.NotContains(t, logger.lines[0], "Bearer")
+	assert.NotContains(t, logger.lines[0], "Basic")
+}
+
+func TestSchemaRegistryClient_SendsNoProxyAuthorizationByDefault(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		username, password, ok := req.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "username", username)
+		assert.Equal(t, "password", password)
+		assert.Empty(t, req.Header.Get("Proxy-Authorization"))
+		rw.Write([]byte("[]"))
+	}))
+
+	srClient := NewSchemaRegistryClient(server.URL)
+	srClient.SetCredentials("username", "password")
+
+	_, err := srClient.GetSubjects()
+
+	require.NoError(t, err)
+}
+
+func TestSchemaRegistryClient_ObservesRequests(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/subjects":
+			rw.Write([]byte("[]"))
+		case "/schemas/ids/1":
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	var observed []RequestInfo
+	srClient := NewSchemaRegistryClient(server.URL, WithRequestObserver(func(info RequestInfo) {
+		observed = append(observed, info)
+	}))
+
+	_, err := srClient.GetSubjects()
+	require.NoError(t, err)
+	_, err = srClient.GetSchema(1)
+	require.Error(t, err)
+
+	require.Len(t, observed, 2)
+	assert.Equal(t, "GET", observed[0].Method)
+	assert.Equal(t, "/subjects", observed[0].URI)
+	assert.Equal(t, http.StatusOK, observed[0].StatusCode)
+	assert.NoError(t, observed[0].Err)
+	assert.Equal(t, "/schemas/ids/1", observed[1].URI)
+	assert.Equal(t, http.StatusNotFound, observed[1].StatusCode)
+	assert.Equal(t, err, observed[1].Err)
+}
+
+func TestSchemaRegistryClient_SendsRequestIDs(t *testing.T) {
+	t.Parallel()
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		received = append(received, req.Header.Get("X-Request-ID"))
+		switch req.URL.String() {
+		case "/subjects":
+			rw.Write([]byte("[]"))
+		case "/schemas/ids/1":
+			if len(received) < 3 {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "payload", ID: 1})
+			rw.Write(response)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	var generated int
+	var observed []RequestInfo
+	logger := &recordingLogger{}
+	srClient := NewSchemaRegistryClient(server.URL,
+		WithRequestIDGenerator(func() string {
+			generated++
+			return fmt.Sprintf("request-%d", generated)
+		}),
+		WithRequestObserver(func(info RequestInfo) {
+			observed = append(observed, info)
+		}),
+		WithLogger(logger),
+		WithRetry(1, time.Millisecond))
+	srClient.CodecCreationEnabled(false)
+
+	_, err := srClient.GetSubjects()
+	require.NoError(t, err)
+	_, err = srClient.GetSchema(1)
+	require.NoError(t, err)
+
+	// Retries are sent with the ID of the call they are part of
+	assert.Equal(t, []string{"request-1", "request-2", "request-2"}, received)
+	require.Len(t, observed, 2)
+	assert.Equal(t, "request-1", observed[0].RequestID)
+	assert.Equal(t, "request-2", observed[1].RequestID)
+	require.Len(t, logger.lines, 3)
+	assert.Contains(t, logger.lines[0], "request-1")
+}
+
+func TestSchemaRegistryClient_SendsNoRequestIDByDefault(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, ok := req.Header["X-Request-Id"]
+		assert.False(t, ok)
+		rw.Write([]byte("[]"))
+	}))
+
+	var observed []RequestInfo
+	srClient := NewSchemaRegistryClient(server.URL, WithRequestObserver(func(info RequestInfo) {
+		observed = append(observed, info)
+	}))
+
+	_, err := srClient.GetSubjects()
+
+	require.NoError(t, err)
+	require.Len(t, observed, 1)
+	assert.Empty(t, observed[0].RequestID)
+}
+
+func TestSchemaRegistryClient_CacheStats(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "payload", ID: 1})
+		rw.Write(response)
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	for i := 0; i < 3; i++ {
+		_, err := srClient.GetSchema(1)
+		require.NoError(t, err)
+		_, err = srClient.GetSchemaByVersion("test1", 1)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, CacheStats{
+		IDCache:      CacheCounters{Hits: 2, Misses: 1, Entries: 1},
+		SubjectCache: CacheCounters{Hits: 2, Misses: 1, Entries: 1},
+	}, srClient.CacheStats())
+
+	srClient.ResetCacheStats()
+	assert.Equal(t, CacheStats{
+		IDCache:      CacheCounters{Entries: 1},
+		SubjectCache: CacheCounters{Entries: 1},
+	}, srClient.CacheStats())
+
+	_, err := srClient.GetSchema(1)
+	require.NoError(t, err)
+	srClient.ResetCache()
+	assert.Equal(t, CacheStats{}, srClient.CacheStats())
+}
+
+func TestSchemaRegistryClient_DeletesEvictCachedVersions(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		deleteFn func(srClient *SchemaRegistryClient) error
+
+		expectedRefetches []string
+	}{
+		"delete subject": {
+			deleteFn: func(srClient *SchemaRegistryClient) error {
+				return srClient.DeleteSubject("test1", false)
+			},
+			expectedRefetches: []string{"/schemas/ids/1", "/subjects/test1/versions/1", "/subjects/test1/versions/latest"},
+		},
+		"delete version": {
+			deleteFn: func(srClient *SchemaRegistryClient) error {
+				return srClient.DeleteSubjectByVersion("test1", 1, false)
+			},
+			expectedRefetches: []string{"/schemas/ids/1", "/subjects/test1/versions/1", "/subjects/test1/versions/latest"},
+		},
+		"delete other version": {
+			deleteFn: func(srClient *SchemaRegistryClient) error {
+				return srClient.DeleteSubjectByVersion("test1", 2, false)
+			},
+			expectedRefetches: []string{"/subjects/test1/versions/latest"},
+		},
+		"purge version": {
+			deleteFn: func(srClient *SchemaRegistryClient) error {
+				return srClient.PurgeSubjectVersion("test1", 1)
+			},
+			expectedRefetches: []string{"/schemas/ids/1", "/subjects/test1/versions/1", "/subjects/test1/versions/latest"},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var requests []string
+			var requestsLock sync.Mutex
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if req.Method == http.MethodDelete {
+					rw.Write([]byte(`[1]`))
+					return
+				}
+				requestsLock.Lock()
+				requests = append(requests, req.URL.String())
+				requestsLock.Unlock()
+				response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "payload", ID: 1})
+				rw.Write(response)
+			}))
+
+			srClient := CreateSchemaRegistryClient(server.URL)
+			srClient.CodecCreationEnabled(false)
+			// Fetching the versions also caches their schema by ID, so the ID is fetched first
+			fetchAll := func() {
+				_, err := srClient.GetSchema(1)
+				require.NoError(t, err)
+				_, err = srClient.GetSchemaByVersion("test1", 1)
+				require.NoError(t, err)
+				_, err = srClient.GetLatestSchema("test1")
+				require.NoError(t, err)
+			}
+			fetchAll()
+			requests = nil
+
+			require.NoError(t, testData.deleteFn(srClient))
+			fetchAll()
+
+			// Test only the deleted versions, and the latest one, are fetched again
+			assert.Equal(t, testData.expectedRefetches, requests)
+		})
+	}
+}
+
+func TestSchemaRegistryClient_GetSchemaByVersionString(t *testing.T) {
+	t.Parallel()
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.URL.String())
+		switch req.URL.String() {
+		case "/subjects/test1/versions/latest", "/subjects/test1/versions/3":
+			response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 3, Schema: "payload", ID: 7})
+			rw.Write(response)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	srClient.CodecCreationEnabled(false)
+
+	latest, err := srClient.GetLatestSchema("test1")
+	require.NoError(t, err)
+	for _, version := range []string{"latest", "LATEST", "-1", "3", "03"} {
+		schema, err := srClient.GetSchemaByVersionString("test1", version)
+		assert.NoError(t, err)
+		assert.Same(t, latest, schema)
+	}
+	schema, err := srClient.GetSchemaByVersion("test1", 3)
+	assert.NoError(t, err)
+	assert.Same(t, latest, schema)
+
+	// Test the latest version and its number share the cached schema
+	assert.Equal(t, []string{"/subjects/test1/versions/latest"}, requests)
+
+	for _, version := range []string{"", "0", "-2", "first", "1/schema"} {
+		_, err := srClient.GetSchemaByVersionString("test1", version)
+		assert.Error(t, err)
+	}
+	assert.Len(t, requests, 1)
+}
+
+func TestSchemaRegistryClient_EvictsSubjectsAndIDs(t *testing.T) {
+	t.Parallel()
+	var requests []string
+	var requestsLock sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requestsLock.Lock()
+		requests = append(requests, req.URL.String())
+		requestsLock.Unlock()
+		response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "payload", ID: 1})
+		rw.Write(response)
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	srClient.CodecCreationEnabled(false)
+	fetchAll := func() {
+		for _, fetch := range []func() (*Schema, error){
+			func() (*Schema, error) { return srClient.GetSchemaByVersion("test1", 1) },
+			func() (*Schema, error) { return srClient.GetLatestSchema("test1") },
+			func() (*Schema, error) { return srClient.GetSchemaByVersion("test1-value", 1) },
+			func() (*Schema, error) { return srClient.GetSchema(2) },
+		} {
+			_, err := fetch()
+			require.NoError(t, err)
+		}
+	}
+	fetchAll()
+	requests = nil
+
+	// Only the versions of test1 are fetched again, not those of test1-value
+	srClient.EvictSubject("test1")
+	fetchAll()
+	assert.Equal(t, []string{"/subjects/test1/versions/1", "/subjects/test1/versions/latest"}, requests)
+	requests = nil
+
+	srClient.EvictSchemaID(2)
+	fetchAll()
+	assert.Equal(t, []string{"/schemas/ids/2"}, requests)
+	assert.Equal(t, 3, srClient.subjectSchemaLRU.Len())
+}
+
+func TestSchemaRegistryClient_CacheTTL(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		ttl           time.Duration
+		expectedCount int32
+	}{
+		"expired entries are fetched again": {
+			ttl:           10 * time.Millisecond,
+			expectedCount: 2,
+		},
+		"entries never expire without ttl": {
+			ttl:           0,
+			expectedCount: 1,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var count int32
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				atomic.AddInt32(&count, 1)
+				response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "payload", ID: 1})
+				rw.Write(response)
+			}))
+
+			srClient := NewSchemaRegistryClient(server.URL, WithCacheTTL(testData.ttl))
+
+			_, err := srClient.GetSchema(1)
+			require.NoError(t, err)
+			time.Sleep(20 * time.Millisecond)
+			schema, err := srClient.GetSchema(1)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "payload", schema.Schema())
+			assert.Equal(t, testData.expectedCount, atomic.LoadInt32(&count))
+		})
+	}
+}
+
+// fakeClock is a Clock whose time only moves when it's advanced or waited on.
+// Waiting returns immediately, recording the delay that was waited for.
+type fakeClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	delays []time.Duration
+}
+
+func (clock *fakeClock) Now() time.Time {
+	clock.lock.Lock()
+	defer clock.lock.Unlock()
+	return clock.now
+}
+
+func (clock *fakeClock) After(d time.Duration) <-chan time.Time {
+	clock.lock.Lock()
+	defer clock.lock.Unlock()
+	clock.now = clock.now.Add(d)
+	clock.delays = append(clock.delays, d)
+	fired := make(chan time.Time, 1)
+	fired <- clock.now
+	return fired
+}
+
+func (clock *fakeClock) Advance(d time.Duration) {
+	clock.lock.Lock()
+	defer clock.lock.Unlock()
+	clock.now = clock.now.Add(d)
+}
+
+func TestSchemaRegistryClient_CacheTTLWithClock(t *testing.T) {
+	t.Parallel()
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&count, 1)
+		response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "payload", ID: 1})
+		rw.Write(response)
+	}))
+
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	srClient := NewSchemaRegistryClient(server.URL, WithCacheTTL(time.Hour), WithClock(clock))
+
+	_, err := srClient.GetSchema(1)
+	require.NoError(t, err)
+	clock.Advance(59 * time.Minute)
+	_, err = srClient.GetSchema(1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+
+	clock.Advance(2 * time.Minute)
+	_, err = srClient.GetSchema(1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+}
+
+func TestSchemaRegistryClient_RetryBackoffWithClock(t *testing.T) {
+	t.Parallel()
+	var count int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		count++
+		if count < 4 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "payload", ID: 1})
+		rw.Write(response)
+	}))
+
+	// The backoff starts from an hour, so the test only completes if the clock is used
+	clock := &fakeClock{}
+	srClient := NewSchemaRegistryClient(server.URL, WithRetry(3, time.Hour), WithClock(clock))
+	_, err := srClient.GetSchema(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, count)
+	require.Len(t, clock.delays, 3)
+	for attempt, delay := range clock.delays {
+		maxDelay := time.Hour << uint(attempt)
+		assert.GreaterOrEqual(t, delay, maxDelay/2)
+		assert.LessOrEqual(t, delay, maxDelay)
+	}
+}
+
+func TestSchemaRegistryClient_GetSchemaType(t *testing.T) {
+	t.Parallel()
+	{
+		expectedSchemaType := Json
+		server, call := mockServerFromSubjectVersionPairWithSchemaResponse(t, "test1-value", "latest", schemaResponse{
+			Subject:    "test1",
+			Version:    1,
+			Schema:     "payload",
+			SchemaType: &expectedSchemaType,
+			ID:         1,
+			References: nil,
+		})
+
+		srClient := CreateSchemaRegistryClient(server.URL)
+		schema, err := srClient.GetLatestSchema("test1-value")
+
+		// Test response
+		assert.NoError(t, err)
+		assert.Equal(t, 1, *call)
+		assert.Equal(t, *schema.SchemaType(), expectedSchemaType)
+	}
+	{
 		server, call := mockServerFromSubjectVersionPairWithSchemaResponse(t, "test1-value", "latest", schemaResponse{
 			Subject:    "test1",
 			Version:    1,
@@ -588,6 +1706,24 @@ func TestSchemaRegistryClient_GetSubjectVersionsById(t *testing.T) {
 	}
 }
 
+func TestSchemaRegistryClient_GetReferencedBy(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/subjects/test1/versions/2/referencedby":
+			rw.Write([]byte(`[3,7]`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	ids, err := srClient.GetReferencedBy("test1", 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 7}, ids)
+}
+
 func TestSchemaRegistryClient_GetSchemaRegistryURL(t *testing.T) {
 	t.Parallel()
 	server, _ := mockServerFromSubjectVersionPairWithSchemaResponse(t, "test1-value", "latest", schemaResponse{
@@ -646,52 +1782,219 @@ func TestSchemaRegistryClient_JsonSchemaParses(t *testing.T) {
 	}
 }
 
-func TestNewSchema(t *testing.T) {
+func TestSchema_CodecWithError(t *testing.T) {
 	t.Parallel()
-	const (
-		anId        = 3
-		aSchema     = "payload"
-		aSchemaType = Protobuf
-		aVersion    = 2
-	)
-	var (
-		reference1 Reference = Reference{
-			Name:    "reference1",
-			Subject: "subject1",
-			Version: 5,
-		}
-		reference2 Reference = Reference{
-			Name:    "reference2",
-			Subject: "subject2",
-			Version: 2,
-		}
-		references                    = []Reference{reference1, reference2}
-		jsonSchema *jsonschema.Schema = &jsonschema.Schema{
-			Location: "aLocation",
-		}
-	)
-	mockCodec, _ := goavro.NewCodec(`"string"`)
 	{
-		_, err := NewSchema(anId, "", aSchemaType, aVersion, nil, nil, nil)
-		assert.EqualError(t, err, "schema cannot be nil")
+		schema, err := NewSchema(1, `"string"`, Avro, 1, nil, nil, nil)
+		assert.NoError(t, err)
+
+		codec, err := schema.CodecWithError()
+
+		// Test the codec is cached for both methods
+		assert.NoError(t, err)
+		assert.NotNil(t, codec)
+		assert.Same(t, codec, schema.Codec())
 	}
 	{
-		schema, err := NewSchema(anId, aSchema, aSchemaType, aVersion, nil, nil, nil)
+		schema, err := NewSchema(1, `{"type": "record"}`, Avro, 1, nil, nil, nil)
 		assert.NoError(t, err)
-		assert.Equal(t, anId, schema.ID())
-		assert.Equal(t, aSchema, schema.Schema())
-		assert.Equal(t, aSchemaType, *schema.SchemaType())
-		assert.Equal(t, aVersion, schema.Version())
-		assert.Nil(t, schema.References())
+
+		codec, err := schema.CodecWithError()
+
+		// Test the compilation error is returned
+		assert.Error(t, err)
+		assert.Nil(t, codec)
 		assert.Nil(t, schema.Codec())
-		assert.Nil(t, schema.JsonSchema())
+	}
+}
+
+func TestSchema_JsonSchemaWithError(t *testing.T) {
+	t.Parallel()
+	{
+		schema, err := NewSchema(1, `{"type": "object"}`, Json, 1, nil, nil, nil)
+		assert.NoError(t, err)
 
+		jsonSchema, err := schema.JsonSchemaWithError()
+
+		// Test the json schema is cached for both methods
+		assert.NoError(t, err)
+		assert.NotNil(t, jsonSchema)
+		assert.Same(t, jsonSchema, schema.JsonSchema())
 	}
 	{
-		schema, err := NewSchema(
-			anId,
-			aSchema,
-			aSchemaType,
+		schema, err := NewSchema(1, `{"type": 1}`, Json, 1, nil, nil, nil)
+		assert.NoError(t, err)
+
+		jsonSchema, err := schema.JsonSchemaWithError()
+
+		// Test the compilation error is returned
+		assert.Error(t, err)
+		assert.Nil(t, jsonSchema)
+		assert.Nil(t, schema.JsonSchema())
+	}
+}
+
+func TestSchema_CreatesCodecAndJsonSchemaConcurrently(t *testing.T) {
+	t.Parallel()
+	avroSchema, err := NewSchema(1, `"string"`, Avro, 1, nil, nil, nil)
+	require.NoError(t, err)
+	jsonSchema, err := NewSchema(2, `{"type": "object"}`, Json, 1, nil, nil, nil)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	codecs := make([]*goavro.Codec, 8)
+	jsonSchemas := make([]*jsonschema.Schema, 8)
+	for i := range codecs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codecs[i] = avroSchema.Codec()
+			jsonSchemas[i] = jsonSchema.JsonSchema()
+		}(i)
+	}
+	wg.Wait()
+
+	// Test every goroutine gets the codec and json schema created once
+	for i := range codecs {
+		assert.Same(t, avroSchema.Codec(), codecs[i])
+		assert.Same(t, jsonSchema.JsonSchema(), jsonSchemas[i])
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		schema     string
+		schemaType SchemaType
+
+		expectError       bool
+		expectInvalid     bool
+		expectUnsupported bool
+	}{
+		"valid avro": {
+			schema:     `{"type": "record", "name": "cupcake", "fields": [{"name": "flavor", "type": "string"}]}`,
+			schemaType: Avro,
+		},
+		"invalid avro": {
+			schema:        `{"type": "record"}`,
+			schemaType:    Avro,
+			expectError:   true,
+			expectInvalid: true,
+		},
+		"valid json": {
+			schema:     `{"type": "object"}`,
+			schemaType: Json,
+		},
+		"invalid json": {
+			schema:        `{"type": 1}`,
+			schemaType:    Json,
+			expectError:   true,
+			expectInvalid: true,
+		},
+		"protobuf is not supported": {
+			schema:            `syntax = "proto3";`,
+			schemaType:        Protobuf,
+			expectError:       true,
+			expectUnsupported: true,
+		},
+		"invalid schema type": {
+			schema:      `"string"`,
+			schemaType:  SchemaType("XML"),
+			expectError: true,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateSchema(testData.schema, testData.schemaType)
+
+			if !testData.expectError {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, testData.expectInvalid, errors.Is(err, ErrInvalidSchema))
+			assert.Equal(t, testData.expectUnsupported, errors.Is(err, ErrValidationNotSupported))
+		})
+	}
+}
+
+func TestSchema_JSONRoundTrip(t *testing.T) {
+	t.Parallel()
+	references := []Reference{{Name: "reference1", Subject: "subject1", Version: 5}}
+	schema, err := NewSchema(3, `"string"`, Avro, 2, references, nil, nil)
+	assert.NoError(t, err)
+	schema.metadata = &Metadata{Properties: map[string]string{"owner": "team1"}}
+	assert.NotNil(t, schema.Codec())
+
+	encoded, err := json.Marshal(schema)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":3,"schema":"\"string\"","schemaType":"AVRO","version":2,`+
+		`"references":[{"name":"reference1","subject":"subject1","version":5}],`+
+		`"metadata":{"properties":{"owner":"team1"}}}`, string(encoded))
+
+	decoded := new(Schema)
+	assert.NoError(t, json.Unmarshal(encoded, decoded))
+	assert.Equal(t, schema.ID(), decoded.ID())
+	assert.Equal(t, schema.Schema(), decoded.Schema())
+	assert.Equal(t, schema.SchemaType(), decoded.SchemaType())
+	assert.Equal(t, schema.Version(), decoded.Version())
+	assert.Equal(t, schema.References(), decoded.References())
+	assert.Equal(t, schema.Metadata(), decoded.Metadata())
+
+	// Test the codec is initialized again when needed
+	assert.Nil(t, decoded.codec)
+	assert.NotNil(t, decoded.Codec())
+}
+
+func TestNewSchema(t *testing.T) {
+	t.Parallel()
+	const (
+		anId        = 3
+		aSchema     = "payload"
+		aSchemaType = Protobuf
+		aVersion    = 2
+	)
+	var (
+		reference1 Reference = Reference{
+			Name:    "reference1",
+			Subject: "subject1",
+			Version: 5,
+		}
+		reference2 Reference = Reference{
+			Name:    "reference2",
+			Subject: "subject2",
+			Version: 2,
+		}
+		references                    = []Reference{reference1, reference2}
+		jsonSchema *jsonschema.Schema = &jsonschema.Schema{
+			Location: "aLocation",
+		}
+	)
+	mockCodec, _ := goavro.NewCodec(`"string"`)
+	{
+		_, err := NewSchema(anId, "", aSchemaType, aVersion, nil, nil, nil)
+		assert.EqualError(t, err, "schema cannot be nil")
+	}
+	{
+		schema, err := NewSchema(anId, aSchema, aSchemaType, aVersion, nil, nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, anId, schema.ID())
+		assert.Equal(t, aSchema, schema.Schema())
+		assert.Equal(t, aSchemaType, *schema.SchemaType())
+		assert.Equal(t, aVersion, schema.Version())
+		assert.Nil(t, schema.References())
+		assert.Nil(t, schema.Codec())
+		assert.Nil(t, schema.JsonSchema())
+
+	}
+	{
+		schema, err := NewSchema(
+			anId,
+			aSchema,
+			aSchemaType,
 			aVersion,
 			references,
 			mockCodec,
@@ -714,6 +2017,8 @@ func TestSchemaRequestMarshal(t *testing.T) {
 		schema     string
 		schemaType SchemaType
 		references []Reference
+		id         int
+		version    int
 		expected   string
 	}{
 		"avro": {
@@ -767,6 +2072,19 @@ func TestSchemaRequestMarshal(t *testing.T) {
 			references: []Reference{{Name: "name1", Subject: "subject1", Version: 1}},
 			expected:   `{"schema":"test2","schemaType":"JSON","references":[{"name":"name1","subject":"subject1","version":1}]}`,
 		},
+		"avro-id-version": {
+			schema:     `test2`,
+			schemaType: Avro,
+			id:         12,
+			version:    3,
+			expected:   `{"schema":"test2","id":12,"version":3}`,
+		},
+		"protobuf-id": {
+			schema:     `test2`,
+			schemaType: Protobuf,
+			id:         12,
+			expected:   `{"schema":"test2","schemaType":"PROTOBUF","id":12}`,
+		},
 	}
 
 	for name, testData := range tests {
@@ -777,6 +2095,8 @@ func TestSchemaRequestMarshal(t *testing.T) {
 				Schema:     testData.schema,
 				SchemaType: testData.schemaType.String(),
 				References: testData.references,
+				ID:         testData.id,
+				Version:    testData.version,
 			}
 			actual, err := json.Marshal(schemaReq)
 			assert.NoError(t, err)
@@ -785,6 +2105,2319 @@ func TestSchemaRequestMarshal(t *testing.T) {
 	}
 }
 
+func TestSchemaRegistryClient_GetAllSchemas(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		latestOnly  bool
+		offset      int
+		limit       int
+		expectedURL string
+	}{
+		"no paging": {
+			expectedURL: "/schemas",
+		},
+		"latest only": {
+			latestOnly:  true,
+			expectedURL: "/schemas?latestOnly=true",
+		},
+		"paged": {
+			offset:      20,
+			limit:       10,
+			expectedURL: "/schemas?limit=10&offset=20",
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, testData.expectedURL, req.URL.String())
+				rw.Write([]byte(`[{"subject":"test1","version":1,"id":1,"schema":"test2"},` +
+					`{"subject":"test2","version":3,"id":5,"schemaType":"PROTOBUF","schema":"test3"}]`))
+			}))
+
+			srClient := CreateSchemaRegistryClient(server.URL)
+			result, err := srClient.GetAllSchemas(testData.latestOnly, testData.offset, testData.limit)
+
+			assert.NoError(t, err)
+			assert.Equal(t, []SchemaMetadata{
+				{Subject: "test1", Version: 1, ID: 1, SchemaType: Avro, Schema: "test2"},
+				{Subject: "test2", Version: 3, ID: 5, SchemaType: Protobuf, Schema: "test3"},
+			}, result)
+		})
+	}
+}
+
+func TestSchemaRegistryClient_DeleteSubjectCompatibilityLevel(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodDelete, req.Method)
+		switch req.URL.String() {
+		case "/config/test1-value":
+			rw.Write([]byte(`{"compatibilityLevel":"FULL"}`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	compatibility, err := srClient.DeleteSubjectCompatibilityLevel("test1-value")
+
+	assert.NoError(t, err)
+	assert.Equal(t, Full, *compatibility)
+}
+
+func TestSchemaRegistryClient_SubjectExists(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		switch req.URL.String() {
+		case "/subjects/test1-value/versions":
+			rw.Write([]byte(`[1,2]`))
+		case "/subjects/test2-value/versions":
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"error_code":40401,"message":"Subject 'test2-value' not found."}`))
+		case "/subjects/test3-value/versions":
+			rw.WriteHeader(http.StatusUnauthorized)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+
+	{
+		exists, err := srClient.SubjectExists("test1-value")
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	}
+	{
+		exists, err := srClient.SubjectExists("test2-value")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	}
+	{
+		exists, err := srClient.SubjectExists("test3-value")
+		assert.Error(t, err)
+		assert.False(t, exists)
+	}
+}
+
+func TestSchemaRegistryClient_GetEffectiveCompatibility(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		switch req.URL.String() {
+		case "/config":
+			rw.Write([]byte(`{"compatibilityLevel":"BACKWARD"}`))
+		case "/config/test1-value?defaultToGlobal=false":
+			rw.Write([]byte(`{"compatibilityLevel":"FULL"}`))
+		case "/config/test2-value?defaultToGlobal=false":
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"error_code":40408,"message":"Subject 'test2-value' does not have subject-level compatibility configured"}`))
+		case "/config/test3-value?defaultToGlobal=false":
+			rw.WriteHeader(http.StatusInternalServerError)
+			rw.Write([]byte(`{"error_code":50001,"message":"Error in the backend data store"}`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+
+	{
+		level, inherited, err := srClient.GetEffectiveCompatibility("test1-value")
+		assert.NoError(t, err)
+		assert.Equal(t, Full, level)
+		assert.False(t, inherited)
+	}
+	{
+		level, inherited, err := srClient.GetEffectiveCompatibility("test2-value")
+		assert.NoError(t, err)
+		assert.Equal(t, Backward, level)
+		assert.True(t, inherited)
+	}
+	{
+		_, _, err := srClient.GetEffectiveCompatibility("test3-value")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrCompatibilityNotConfigured)
+	}
+}
+
+func TestSchemaRegistryClient_GetConfig(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		switch req.URL.String() {
+		case "/config":
+			rw.Write([]byte(`{"compatibilityLevel":"BACKWARD","normalize":true,"compatibilityGroup":"application.major.version",` +
+				`"defaultMetadata":{"properties":{"owner":"bakery"}},"defaultRuleSet":{"domainRules":[{"name":"checkFlavor","kind":"CONDITION"}]}}`))
+		case "/config/test1-value":
+			rw.Write([]byte(`{"compatibilityLevel":"FULL"}`))
+		case "/config/test2-value":
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"error_code":40408,"message":"Subject 'test2-value' does not have subject-level compatibility configured"}`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+
+	{
+		registryConfig, err := srClient.GetGlobalConfig()
+		normalize := true
+		assert.NoError(t, err)
+		assert.Equal(t, &RegistryConfig{
+			Normalize:          &normalize,
+			CompatibilityLevel: Backward,
+			CompatibilityGroup: "application.major.version",
+			DefaultMetadata:    &Metadata{Properties: map[string]string{"owner": "bakery"}},
+			DefaultRuleSet:     &RuleSet{DomainRules: []Rule{{Name: "checkFlavor", Kind: "CONDITION"}}},
+		}, registryConfig)
+	}
+	{
+		registryConfig, err := srClient.GetSubjectConfig("test1-value")
+		assert.NoError(t, err)
+		assert.Equal(t, &RegistryConfig{CompatibilityLevel: Full}, registryConfig)
+	}
+	{
+		registryConfig, err := srClient.GetSubjectConfig("test2-value")
+		assert.Nil(t, registryConfig)
+		assert.ErrorIs(t, err, ErrCompatibilityNotConfigured)
+	}
+}
+
+func TestSchemaRegistryClient_UpdateGlobalConfig(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		registryConfig RegistryConfig
+		expectedBody   string
+	}{
+		"compatibility level only": {
+			registryConfig: RegistryConfig{CompatibilityLevel: Full},
+			expectedBody:   `{"compatibility":"FULL"}`,
+		},
+		"normalize only": {
+			registryConfig: RegistryConfig{Normalize: &[]bool{false}[0]},
+			expectedBody:   `{"normalize":false}`,
+		},
+		"normalize and compatibility level": {
+			registryConfig: RegistryConfig{Normalize: &[]bool{true}[0], CompatibilityLevel: FullTransitive},
+			expectedBody:   `{"normalize":true,"compatibility":"FULL_TRANSITIVE"}`,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, http.MethodPut, req.Method)
+				assert.Equal(t, "/config", req.URL.String())
+				body, _ := io.ReadAll(req.Body)
+				assert.JSONEq(t, testData.expectedBody, string(body))
+				// Schema Registry echoes the update back
+				rw.Write(body)
+			}))
+
+			srClient := CreateSchemaRegistryClient(server.URL)
+			registryConfig, err := srClient.UpdateGlobalConfig(testData.registryConfig)
+
+			assert.NoError(t, err)
+			assert.Equal(t, &testData.registryConfig, registryConfig)
+		})
+	}
+}
+
+func TestSchemaRegistryClient_GetMode(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		switch req.URL.String() {
+		case "/mode":
+			rw.Write([]byte(`{"mode":"READWRITE"}`))
+		case "/mode/test1-value":
+			rw.Write([]byte(`{"mode":"READONLY"}`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+
+	globalMode, err := srClient.GetGlobalMode()
+	assert.NoError(t, err)
+	assert.Equal(t, ReadWrite, globalMode)
+
+	subjectMode, err := srClient.GetMode("test1-value")
+	assert.NoError(t, err)
+	assert.Equal(t, ReadOnly, subjectMode)
+}
+
+func TestSchemaRegistryClient_UpdateMode(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		force       bool
+		expectedURL string
+	}{
+		"without force": {
+			force:       false,
+			expectedURL: "/mode/test1-value",
+		},
+		"with force": {
+			force:       true,
+			expectedURL: "/mode/test1-value?force=true",
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, http.MethodPut, req.Method)
+				assert.Equal(t, testData.expectedURL, req.URL.String())
+				assert.Equal(t, `{"mode":"IMPORT"}`, bodyToString(req.Body))
+				rw.Write([]byte(`{"mode":"IMPORT"}`))
+			}))
+
+			srClient := CreateSchemaRegistryClient(server.URL)
+			mode, err := srClient.UpdateMode("test1-value", Import, testData.force)
+
+			assert.NoError(t, err)
+			assert.Equal(t, Import, mode)
+		})
+	}
+}
+
+func TestSchemaRegistryClient_EscapesSubjectsInPaths(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		call             func(srClient *SchemaRegistryClient) error
+		expectedRequests []string
+	}{
+		"delete subject": {
+			call: func(srClient *SchemaRegistryClient) error {
+				return srClient.DeleteSubject("orders/value v1", true)
+			},
+			expectedRequests: []string{"/subjects/orders%2Fvalue%20v1", "/subjects/orders%2Fvalue%20v1?permanent=true"},
+		},
+		"delete subject version": {
+			call: func(srClient *SchemaRegistryClient) error {
+				return srClient.DeleteSubjectByVersion("orders/value v1", 1, true)
+			},
+			expectedRequests: []string{"/subjects/orders%2Fvalue%20v1/versions/1", "/subjects/orders%2Fvalue%20v1/versions/1?permanent=true"},
+		},
+		"delete latest subject version": {
+			call: func(srClient *SchemaRegistryClient) error {
+				_, err := srClient.DeleteSubjectVersionLatest("orders/value v1", true)
+				return err
+			},
+			expectedRequests: []string{"/subjects/orders%2Fvalue%20v1/versions/latest", "/subjects/orders%2Fvalue%20v1/versions/1?permanent=true"},
+		},
+		"purge subject version": {
+			call: func(srClient *SchemaRegistryClient) error {
+				return srClient.PurgeSubjectVersion("orders/value v1", 1)
+			},
+			expectedRequests: []string{"/subjects/orders%2Fvalue%20v1/versions/1", "/subjects/orders%2Fvalue%20v1/versions/1?permanent=true"},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var requests []string
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				requests = append(requests, req.URL.String())
+				rw.Write([]byte(`1`))
+			}))
+
+			err := testData.call(CreateSchemaRegistryClient(server.URL))
+
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedRequests, requests)
+		})
+	}
+}
+
+func TestSchemaRegistryClient_DeleteSubjectVersionLatest(t *testing.T) {
+	t.Parallel()
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodDelete, req.Method)
+		requests = append(requests, req.URL.String())
+		switch req.URL.String() {
+		case "/subjects/test1/versions/latest":
+			rw.Write([]byte(`4`))
+		case "/subjects/test1/versions/4?permanent=true":
+			rw.Write([]byte(`4`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+
+	{
+		version, err := srClient.DeleteSubjectVersionLatest("test1", false)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, version)
+		assert.Equal(t, []string{"/subjects/test1/versions/latest"}, requests)
+	}
+	requests = nil
+	{
+		version, err := srClient.DeleteSubjectVersionLatest("test1", true)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, version)
+		assert.Equal(t, []string{"/subjects/test1/versions/latest", "/subjects/test1/versions/4?permanent=true"}, requests)
+	}
+}
+
+func TestSchemaRegistryClient_PurgeSubjectVersion(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		softDeleteStatus int
+		softDeleteBody   string
+		hardDeleteStatus int
+		hardDeleteBody   string
+
+		expectedCalls       int
+		expectError         bool
+		expectedSoftDeleted bool
+	}{
+		"both deletes succeed": {
+			softDeleteStatus: http.StatusOK,
+			softDeleteBody:   `1`,
+			hardDeleteStatus: http.StatusOK,
+			hardDeleteBody:   `1`,
+			expectedCalls:    2,
+		},
+		"already soft deleted": {
+			softDeleteStatus: http.StatusNotFound,
+			softDeleteBody:   `{"error_code":40406,"message":"Subject 'test1' Version 1 was soft deleted"}`,
+			hardDeleteStatus: http.StatusOK,
+			hardDeleteBody:   `1`,
+			expectedCalls:    2,
+		},
+		"already hard deleted": {
+			softDeleteStatus: http.StatusOK,
+			softDeleteBody:   `1`,
+			hardDeleteStatus: http.StatusNotFound,
+			hardDeleteBody:   `{"error_code":40402,"message":"Version 1 not found."}`,
+			expectedCalls:    2,
+		},
+		"subject already purged": {
+			softDeleteStatus: http.StatusNotFound,
+			softDeleteBody:   `{"error_code":40401,"message":"Subject 'test1' not found."}`,
+			expectedCalls:    1,
+		},
+		"version already purged": {
+			softDeleteStatus: http.StatusNotFound,
+			softDeleteBody:   `{"error_code":40402,"message":"Version 1 not found."}`,
+			expectedCalls:    1,
+		},
+		"soft delete fails": {
+			softDeleteStatus: http.StatusInternalServerError,
+			softDeleteBody:   `{"error_code":50001,"message":"Error in the backend data store"}`,
+			expectedCalls:    1,
+			expectError:      true,
+		},
+		"hard delete fails": {
+			softDeleteStatus:    http.StatusOK,
+			softDeleteBody:      `1`,
+			hardDeleteStatus:    http.StatusInternalServerError,
+			hardDeleteBody:      `{"error_code":50001,"message":"Error in the backend data store"}`,
+			expectedCalls:       2,
+			expectError:         true,
+			expectedSoftDeleted: true,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var count int
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				count++
+				assert.Equal(t, http.MethodDelete, req.Method)
+				switch req.URL.String() {
+				case "/subjects/test1/versions/1":
+					rw.WriteHeader(testData.softDeleteStatus)
+					rw.Write([]byte(testData.softDeleteBody))
+				case "/subjects/test1/versions/1?permanent=true":
+					rw.WriteHeader(testData.hardDeleteStatus)
+					rw.Write([]byte(testData.hardDeleteBody))
+				default:
+					require.Fail(t, "unhandled request")
+				}
+			}))
+
+			srClient := CreateSchemaRegistryClient(server.URL)
+			err := srClient.PurgeSubjectVersion("test1", 1)
+
+			assert.Equal(t, testData.expectedCalls, count)
+			if !testData.expectError {
+				assert.NoError(t, err)
+				return
+			}
+			var purgeErr *PurgeError
+			if assert.True(t, errors.As(err, &purgeErr)) {
+				assert.Equal(t, testData.expectedSoftDeleted, purgeErr.SoftDeleted)
+			}
+		})
+	}
+}
+
+func TestSchemaRegistryClient_RetriesTransientErrors(t *testing.T) {
+	t.Parallel()
+	{
+		var count int
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			count++
+			if count < 3 {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "payload", ID: 1})
+			rw.Write(response)
+		}))
+
+		srClient := NewSchemaRegistryClient(server.URL, WithRetry(3, time.Millisecond))
+		schema, err := srClient.GetSchema(1)
+
+		// Test response
+		assert.NoError(t, err)
+		assert.Equal(t, 3, count)
+		assert.Equal(t, "payload", schema.Schema())
+	}
+	{
+		var count int
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			count++
+			rw.WriteHeader(http.StatusBadGateway)
+		}))
+
+		srClient := NewSchemaRegistryClient(server.URL, WithRetry(2, time.Millisecond))
+		_, err := srClient.GetSchema(1)
+
+		// Test all attempts are reported
+		assert.Error(t, err)
+		assert.Equal(t, 3, count)
+		var retryErr *RetryError
+		if assert.True(t, errors.As(err, &retryErr)) {
+			assert.Equal(t, 3, retryErr.Attempts)
+			assert.EqualError(t, retryErr.Err, "502 Bad Gateway")
+		}
+	}
+}
+
+func TestSchemaRegistryClient_RetriesThrottledRequestsAfterRetryAfter(t *testing.T) {
+	t.Parallel()
+	var count int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		count++
+		if count == 1 {
+			rw.Header().Set("Retry-After", "0")
+			rw.WriteHeader(http.StatusTooManyRequests)
+			rw.Write([]byte(`{"error_code":42901,"message":"Too many requests"}`))
+			return
+		}
+		response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "payload", ID: 1})
+		rw.Write(response)
+	}))
+
+	// The backoff would be an hour, so the test only completes if Retry-After is honored
+	srClient := NewSchemaRegistryClient(server.URL, WithRetry(1, time.Hour))
+	schema, err := srClient.GetSchema(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, "payload", schema.Schema())
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		header        string
+		expectedOk    bool
+		expectedDelay time.Duration
+	}{
+		"seconds": {
+			header:        "120",
+			expectedOk:    true,
+			expectedDelay: 2 * time.Minute,
+		},
+		"past date": {
+			header:     "Wed, 21 Oct 2015 07:27:00 GMT",
+			expectedOk: true,
+		},
+		"future date": {
+			header:        "Wed, 21 Oct 2015 07:29:30 GMT",
+			expectedOk:    true,
+			expectedDelay: 90 * time.Second,
+		},
+		"missing": {},
+		"invalid": {
+			header: "soon",
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			clock := &fakeClock{now: time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)}
+			delay, ok := retryAfter(clock, Error{StatusCode: http.StatusTooManyRequests, RetryAfter: testData.header})
+
+			assert.Equal(t, testData.expectedOk, ok)
+			assert.Equal(t, testData.expectedDelay, delay)
+		})
+	}
+}
+
+type tokenSourceFunc func() (string, error)
+
+func (fn tokenSourceFunc) Token() (string, error) {
+	return fn()
+}
+
+func TestSchemaRegistryClient_RotatesCredentialsConcurrently(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte(fmt.Sprintf("[%q]", req.Header.Get("Authorization"))))
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	srClient.SetBearerToken("token0")
+
+	// Run with -race to detect unsynchronized access to the credentials
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 50; i++ {
+			srClient.SetBearerToken(fmt.Sprintf("token%d", i))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			authorization, err := srClient.GetSubjects()
+			assert.NoError(t, err)
+			assert.Len(t, authorization, 1)
+			assert.True(t, strings.HasPrefix(authorization[0], "Bearer token"))
+		}
+	}()
+	wg.Wait()
+}
+
+func TestSchemaRegistryClient_WithTokenSource(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte(fmt.Sprintf("[%q]", req.Header.Get("Authorization"))))
+	}))
+
+	var count int
+	srClient := NewSchemaRegistryClient(server.URL, WithTokenSource(tokenSourceFunc(func() (string, error) {
+		count++
+		if count > 2 {
+			return "", errors.New("token expired")
+		}
+		return fmt.Sprintf("token%d", count), nil
+	})))
+	srClient.SetBearerToken("static")
+
+	{
+		// Test a fresh token is fetched for every request
+		for i := 1; i <= 2; i++ {
+			authorization, err := srClient.GetSubjects()
+			assert.NoError(t, err)
+			assert.Equal(t, []string{fmt.Sprintf("Bearer token%d", i)}, authorization)
+		}
+	}
+	{
+		// Test the request fails when no token can be fetched
+		_, err := srClient.GetSubjects()
+		assert.EqualError(t, err, "failed to get a token from the token source: token expired")
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+func TestSchemaRegistryClient_BearerTokenScheme(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		schemaRegistryURL     string
+		expectedAuthorization string
+	}{
+		"confluent cloud": {
+			schemaRegistryURL:     "https://psrc-abcde.us-east-2.aws.confluent.cloud",
+			expectedAuthorization: "Basic token",
+		},
+		"self-managed": {
+			schemaRegistryURL:     "https://schema-registry.example.com",
+			expectedAuthorization: "Bearer token",
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var authorization string
+			httpClient := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				authorization = req.Header.Get("Authorization")
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("[]")),
+				}, nil
+			})}
+
+			srClient := NewSchemaRegistryClient(testData.schemaRegistryURL, WithClient(httpClient))
+			srClient.SetBearerToken("token")
+			_, err := srClient.GetSubjects()
+
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expectedAuthorization, authorization)
+		})
+	}
+}
+
+func TestSchemaRegistryClient_SemaphoreAcquireTimesOut(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte("[]"))
+	}))
+
+	srClient := NewSchemaRegistryClient(server.URL, WithSemaphoreWeight(1))
+	srClient.SetTimeout(50 * time.Millisecond)
+	// Hold the only permit, like a request stuck in Schema Registry would
+	require.NoError(t, srClient.sem.Acquire(context.Background(), 1))
+
+	start := time.Now()
+	_, err := srClient.GetSubjects()
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+
+	srClient.sem.Release(1)
+	_, err = srClient.GetSubjects()
+	assert.NoError(t, err)
+}
+
+func TestSchemaRegistryClient_BaseURLWithPath(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/schema-registry/subjects":
+			rw.Write([]byte(`["test1"]`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	for _, schemaRegistryURL := range []string{server.URL + "/schema-registry", server.URL + "/schema-registry/"} {
+		srClient := CreateSchemaRegistryClient(schemaRegistryURL)
+		subjects, err := srClient.GetSubjects()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"test1"}, subjects)
+	}
+}
+
+func TestSchemaRegistryClient_WithHeader(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "tenant1", req.Header.Get("X-Tenant-ID"))
+		assert.Equal(t, []string{"a", "b"}, req.Header.Values("X-Trace"))
+		assert.Equal(t, contentType, req.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+		rw.Write([]byte("[]"))
+	}))
+
+	srClient := NewSchemaRegistryClient(server.URL,
+		WithHeader("X-Tenant-ID", "tenant1"),
+		WithHeader("X-Trace", "a"),
+		WithHeader("X-Trace", "b"))
+	srClient.SetBearerToken("token")
+	_, err := srClient.GetSubjects()
+
+	assert.NoError(t, err)
+}
+
+func TestSchemaRegistryClient_UserAgent(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		options           []Option
+		expectedUserAgent string
+	}{
+		"default": {
+			options:           nil,
+			expectedUserAgent: defaultUserAgent,
+		},
+		"custom": {
+			options:           []Option{WithUserAgent("orders-service/1.2.3")},
+			expectedUserAgent: "orders-service/1.2.3",
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Write([]byte(fmt.Sprintf("[%q]", req.Header.Get("User-Agent"))))
+			}))
+
+			srClient := NewSchemaRegistryClient(server.URL, testData.options...)
+			userAgent, err := srClient.GetSubjects()
+
+			assert.NoError(t, err)
+			assert.Equal(t, []string{testData.expectedUserAgent}, userAgent)
+		})
+	}
+	assert.True(t, strings.HasPrefix(defaultUserAgent, "srclient"))
+}
+
+func TestSchemaRegistryClient_WithTLSConfig(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+	srClient := NewSchemaRegistryClient(server.URL, WithTLSConfig(&tls.Config{RootCAs: rootCAs}))
+
+	subjects, err := srClient.GetSubjects()
+
+	assert.NoError(t, err)
+	assert.Empty(t, subjects)
+	assert.Equal(t, defaultTimeout, srClient.httpClient.Timeout)
+}
+
+func TestSchemaRegistryClient_WithInsecureSkipVerify(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	{
+		srClient := NewSchemaRegistryClient(server.URL)
+
+		_, err := srClient.GetSubjects()
+
+		// Test the certificate is verified by default
+		assert.Error(t, err)
+	}
+	{
+		srClient := NewSchemaRegistryClient(server.URL, WithInsecureSkipVerify())
+
+		subjects, err := srClient.GetSubjects()
+
+		// Test the certificate isn't verified
+		assert.NoError(t, err)
+		assert.Empty(t, subjects)
+		assert.Equal(t, defaultTimeout, srClient.httpClient.Timeout)
+	}
+	{
+		jar, _ := cookiejar.New(nil)
+		transport := &http.Transport{TLSClientConfig: &tls.Config{ServerName: "example.com"}}
+		client := &http.Client{
+			Transport:     transport,
+			Jar:           jar,
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+			Timeout:       time.Minute,
+		}
+		srClient := NewSchemaRegistryClient(server.URL, WithClient(client), WithInsecureSkipVerify())
+
+		// Test only the transport of the client is replaced, with a copy of its own
+		assert.Equal(t, jar, srClient.httpClient.Jar)
+		assert.NotNil(t, srClient.httpClient.CheckRedirect)
+		assert.Equal(t, time.Minute, srClient.httpClient.Timeout)
+		insecureTransport := srClient.httpClient.Transport.(*http.Transport)
+		assert.True(t, insecureTransport.TLSClientConfig.InsecureSkipVerify)
+		assert.Equal(t, "example.com", insecureTransport.TLSClientConfig.ServerName)
+		assert.False(t, transport.TLSClientConfig.InsecureSkipVerify)
+		assert.Equal(t, transport, client.Transport)
+	}
+	{
+		roundTripper := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("unreachable")
+		})
+		client := &http.Client{Transport: roundTripper}
+		srClient := NewSchemaRegistryClient(server.URL, WithClient(client), WithInsecureSkipVerify())
+
+		// Test clients with other round trippers are left unchanged
+		assert.Same(t, client, srClient.httpClient)
+	}
+}
+
+func TestSchemaRegistryClient_GetAllVersionsForSubject(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/subjects/test1/versions":
+			rw.Write([]byte("[1,2,3]"))
+		case "/subjects/test1/versions/1", "/subjects/test1/versions/3":
+			version, _ := strconv.Atoi(strings.TrimPrefix(req.URL.String(), "/subjects/test1/versions/"))
+			response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: version, Schema: "payload", ID: version})
+			rw.Write(response)
+		case "/subjects/test1/versions/2":
+			rw.WriteHeader(http.StatusInternalServerError)
+			rw.Write([]byte(`{"error_code":50001,"message":"Error in the backend data store"}`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	srClient.CodecCreationEnabled(false)
+	schemas, err := srClient.GetAllVersionsForSubject("test1")
+
+	if assert.Len(t, schemas, 2) {
+		assert.Equal(t, 1, schemas[0].Version())
+		assert.Equal(t, 3, schemas[1].Version())
+	}
+	var versionsErr *SchemaVersionsError
+	if assert.ErrorAs(t, err, &versionsErr) {
+		assert.Equal(t, "test1", versionsErr.Subject)
+		assert.Len(t, versionsErr.Errors, 1)
+		assert.Contains(t, versionsErr.Errors, 2)
+	}
+}
+
+func TestSchemaRegistryClient_ResolveVersionByContent(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		var response []byte
+		switch req.URL.String() {
+		case "/subjects/test1/versions":
+			response = []byte(`[1,2,3]`)
+		case "/subjects/test1/versions/1":
+			response, _ = json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: `{"type":"string"}`, ID: 4})
+		case "/subjects/test1/versions/2":
+			response, _ = json.Marshal(schemaResponse{Subject: "test1", Version: 2, Schema: `{"type":"int"}`, ID: 5})
+		case "/subjects/test1/versions/3":
+			rw.WriteHeader(http.StatusInternalServerError)
+			rw.Write([]byte(`{"error_code":50001,"message":"Error in the backend data store"}`))
+			return
+		case "/subjects/test2/versions":
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"error_code":40401,"message":"Subject 'test2' not found."}`))
+			return
+		default:
+			require.Fail(t, "unhandled request")
+		}
+		rw.Write(response)
+	}))
+
+	srClient := NewSchemaRegistryClient(server.URL, WithSemaphoreWeight(1))
+	srClient.CodecCreationEnabled(false)
+
+	{
+		version, err := srClient.ResolveVersionByContent("test1", "{\n  \"type\": \"int\"\n}", Avro)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, version)
+	}
+	{
+		// Test versions that can't be fetched fail the call when no other version matches
+		_, err := srClient.ResolveVersionByContent("test1", `{"type":"long"}`, Avro)
+		var versionsErr *SchemaVersionsError
+		assert.ErrorAs(t, err, &versionsErr)
+	}
+	{
+		_, err := srClient.ResolveVersionByContent("test1", `{"type":"int"}`, Json)
+		assert.Error(t, err)
+	}
+	{
+		_, err := srClient.ResolveVersionByContent("test2", `{"type":"int"}`, Avro)
+		assert.ErrorIs(t, err, ErrSubjectNotFound)
+	}
+}
+
+func TestSchemaRegistryClient_ReimportSubject(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var response []byte
+		switch req.URL.String() {
+		case "/subjects/test1-value/versions":
+			response = []byte(`[2,1]`)
+		case "/subjects/test1-value/versions/1":
+			response, _ = json.Marshal(schemaResponse{Subject: "test1-value", Version: 1, Schema: `"string"`, ID: 7})
+		case "/subjects/test1-value/versions/2":
+			response, _ = json.Marshal(schemaResponse{Subject: "test1-value", Version: 2, Schema: `"int"`, ID: 8})
+		default:
+			require.Fail(t, "unhandled request")
+		}
+		rw.Write(response)
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	target := CreateMockSchemaRegistryClient("http://localhost:8082")
+	err := srClient.ReimportSubject(target, "test1-value")
+
+	assert.NoError(t, err)
+	reimported, err := target.GetAllVersionsForSubject("test1-value")
+	assert.NoError(t, err)
+	require.Len(t, reimported, 2)
+	assert.Equal(t, `"string"`, reimported[0].Schema())
+	assert.Equal(t, Avro, *reimported[0].SchemaType())
+	assert.Equal(t, `"int"`, reimported[1].Schema())
+}
+
+func TestSchemaRegistryClient_GetSchemaExpanded(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var response []byte
+		switch req.URL.String() {
+		case "/schemas/ids/2":
+			response, _ = json.Marshal(schemaResponse{
+				Schema:     `{"type":"record","name":"Cupcake","fields":[{"name":"flavor","type":"Flavor"}]}`,
+				ID:         2,
+				References: []Reference{{Name: "Flavor", Subject: "flavor", Version: 1}},
+			})
+		case "/subjects/flavor/versions/1":
+			response, _ = json.Marshal(schemaResponse{
+				Subject: "flavor",
+				Version: 1,
+				Schema:  `{"type":"enum","name":"Flavor","symbols":["VANILLA"]}`,
+				ID:      1,
+			})
+		default:
+			require.Fail(t, "unhandled request")
+		}
+		rw.Write(response)
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	srClient.CodecCreationEnabled(false)
+	expanded, err := srClient.GetSchemaExpanded(2)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"record","name":"Cupcake","fields":[`+
+		`{"name":"flavor","type":{"type":"enum","name":"Flavor","symbols":["VANILLA"]}}]}`, expanded)
+}
+
+func TestSchemaRegistryClient_DiffSchemaVersions(t *testing.T) {
+	t.Parallel()
+	jsonType := Json
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var response []byte
+		switch req.URL.String() {
+		case "/subjects/test1-value/versions/1":
+			response, _ = json.Marshal(schemaResponse{
+				Subject: "test1-value",
+				Version: 1,
+				Schema:  `{"type":"record","name":"Cupcake","fields":[{"name":"flavor","type":"string"}]}`,
+				ID:      1,
+			})
+		case "/subjects/test1-value/versions/2":
+			response, _ = json.Marshal(schemaResponse{
+				Subject: "test1-value",
+				Version: 2,
+				Schema:  `{"type":"record","name":"Cupcake","fields":[{"name":"flavor","type":"string"},{"name":"size","type":"int","default":1}]}`,
+				ID:      2,
+			})
+		case "/subjects/test2-value/versions/1":
+			response, _ = json.Marshal(schemaResponse{
+				Subject:    "test2-value",
+				Version:    1,
+				Schema:     `{"type":"object"}`,
+				SchemaType: &jsonType,
+				ID:         3,
+			})
+		default:
+			require.Fail(t, "unhandled request")
+		}
+		rw.Write(response)
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	srClient.CodecCreationEnabled(false)
+	srClient.CodecJsonEnabled(false)
+
+	{
+		diff, err := srClient.DiffSchemaVersions("test1-value", 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, SchemaDiff{
+			Subject:     "test1-value",
+			FromVersion: 1,
+			ToVersion:   2,
+			Added:       []SchemaField{{Name: "size", Type: "int", Default: json.RawMessage(`1`)}},
+		}, diff)
+	}
+	{
+		_, err := srClient.DiffSchemaVersions("test2-value", 1, 1)
+		assert.ErrorIs(t, err, ErrDiffUnsupported)
+	}
+}
+
+func TestSchemaRegistryClient_GetSchemaFormat(t *testing.T) {
+	t.Parallel()
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.URL.String())
+		var response []byte
+		switch req.URL.String() {
+		case "/schemas/ids/1?format=serialized":
+			response, _ = json.Marshal(schemaResponse{Schema: "Cg10ZXN0LnByb3Rv", SchemaType: &protobuf, ID: 1})
+		case "/schemas/ids/1":
+			response, _ = json.Marshal(schemaResponse{Schema: `syntax = "proto3";`, SchemaType: &protobuf, ID: 1})
+		default:
+			require.Fail(t, "unhandled request")
+		}
+		rw.Write(response)
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+
+	{
+		schema, err := srClient.GetSchemaFormat(1, "serialized")
+		assert.NoError(t, err)
+		assert.Equal(t, "Cg10ZXN0LnByb3Rv", schema.Schema())
+	}
+	{
+		// Schemas in a format are not cached, so they can't be returned instead of the default one
+		schema, err := srClient.GetSchemaFormat(1, "")
+		assert.NoError(t, err)
+		assert.Equal(t, `syntax = "proto3";`, schema.Schema())
+		schema, err = srClient.GetSchema(1)
+		assert.NoError(t, err)
+		assert.Equal(t, `syntax = "proto3";`, schema.Schema())
+	}
+	assert.Equal(t, []string{"/schemas/ids/1?format=serialized", "/schemas/ids/1"}, requests)
+}
+
+func TestSchemaRegistryClient_GetLatestSchemaWithReferences(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var response []byte
+		switch req.URL.String() {
+		case "/subjects/test1-value/versions/latest":
+			response, _ = json.Marshal(schemaResponse{
+				Subject:    "test1-value",
+				Version:    2,
+				Schema:     `{"type":"record","name":"Cupcake","fields":[{"name":"flavor","type":"Flavor"}]}`,
+				ID:         2,
+				References: []Reference{{Name: "Flavor", Subject: "flavor", Version: 1}},
+			})
+		case "/subjects/flavor/versions/1":
+			response, _ = json.Marshal(schemaResponse{
+				Subject: "flavor",
+				Version: 1,
+				Schema:  `{"type":"enum","name":"Flavor","symbols":["VANILLA"]}`,
+				ID:      1,
+			})
+		default:
+			require.Fail(t, "unhandled request")
+		}
+		rw.Write(response)
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	schema, references, err := srClient.GetLatestSchemaWithReferences("test1-value")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, schema.ID())
+	require.Len(t, references, 1)
+	assert.Equal(t, 1, references["Flavor"].ID())
+	assert.Equal(t, `{"type":"enum","name":"Flavor","symbols":["VANILLA"]}`, references["Flavor"].Schema())
+}
+
+func TestSchemaRegistryClient_GetSchemaWithSubjects(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/schemas/ids/1/schema":
+			rw.Write([]byte(`{"type":"string"}`))
+		case "/schemas/ids/1/versions":
+			rw.Write([]byte(`[{"subject":"test1","version":2},{"subject":"test2","version":1}]`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	schema, subjectVersions, err := srClient.GetSchemaWithSubjects(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"string"}`, schema)
+	assert.Equal(t, SubjectVersionResponse{
+		{Subject: "test1", Version: 2},
+		{Subject: "test2", Version: 1},
+	}, subjectVersions)
+}
+
+func TestSchemaRegistryClient_GetLatestSchemaMetadata(t *testing.T) {
+	t.Parallel()
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/subjects/test1/versions/latest":
+			atomic.AddInt32(&count, 1)
+			response, _ := json.Marshal(schemaResponse{
+				Subject:    "test1",
+				Version:    2,
+				Schema:     `syntax = "proto3";`,
+				ID:         3,
+				SchemaType: &protobuf,
+			})
+			rw.Write(response)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+
+	for _, codecCreationEnabled := range []bool{true, false} {
+		// Test no codec is created for the Protobuf schema
+		srClient.CodecCreationEnabled(codecCreationEnabled)
+		for i := 0; i < 2; i++ {
+			metadata, err := srClient.GetLatestSchemaMetadata("test1")
+
+			assert.NoError(t, err)
+			assert.Equal(t, &SchemaMetadata{
+				Subject:    "test1",
+				Version:    2,
+				ID:         3,
+				SchemaType: Protobuf,
+				Schema:     `syntax = "proto3";`,
+			}, metadata)
+		}
+	}
+	// Schemas without the codec are only cached when codec creation is disabled
+	assert.Equal(t, int32(3), atomic.LoadInt32(&count))
+}
+
+func TestSchemaRegistryClient_GetLatestSchemaMetadataDoesNotCacheSchemasWithoutCodec(t *testing.T) {
+	t.Parallel()
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/subjects/test1/versions/latest":
+			atomic.AddInt32(&count, 1)
+			response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: `{"type":"string"}`, ID: 1})
+			rw.Write(response)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	srClient.CodecCreationEnabled(true)
+
+	_, err := srClient.GetLatestSchemaMetadata("test1")
+	assert.NoError(t, err)
+	schema, err := srClient.GetLatestSchema("test1")
+	assert.NoError(t, err)
+	assert.NotNil(t, schema.Codec())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+
+	// The schema with its codec is cached and serves both
+	_, err = srClient.GetLatestSchemaMetadata("test1")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+}
+
+func TestSchemaRegistryClient_GetRawSchema(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/schemas/ids/1/schema":
+			rw.Write([]byte(`{"type":"string"}`))
+		case "/schemas/ids/2/schema":
+			rw.Write([]byte(`syntax = "proto3";`))
+		case "/schemas/ids/3":
+			response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "payload", ID: 3})
+			rw.Write(response)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+
+	{
+		// Test Avro and Json schemas are returned as they are registered
+		schema, err := srClient.GetRawSchema(1)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"type":"string"}`, schema)
+	}
+	{
+		// Test Protobuf schemas are returned as they are registered
+		schema, err := srClient.GetRawSchema(2)
+		assert.NoError(t, err)
+		assert.Equal(t, `syntax = "proto3";`, schema)
+	}
+	{
+		// Test cached schemas are not requested again
+		_, err := srClient.GetSchema(3)
+		require.NoError(t, err)
+		schema, err := srClient.GetRawSchema(3)
+		assert.NoError(t, err)
+		assert.Equal(t, "payload", schema)
+	}
+	// Test only GetSchema counts a miss, as GetRawSchema doesn't cache what it fetches
+	assert.Equal(t, uint64(1), srClient.CacheStats().IDCache.Misses)
+	assert.Equal(t, uint64(1), srClient.CacheStats().IDCache.Hits)
+}
+
+func TestSchemaRegistryClient_StreamSubjects(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/subjects":
+			rw.Write([]byte(`["test1", "test2", "test3"]`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+
+	{
+		// Test every subject is streamed
+		var subjects []string
+		err := srClient.StreamSubjects(func(subject string) error {
+			subjects = append(subjects, subject)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"test1", "test2", "test3"}, subjects)
+	}
+	{
+		// Test streaming stops when the callback fails
+		var subjects []string
+		stop := errors.New("stop")
+		err := srClient.StreamSubjects(func(subject string) error {
+			subjects = append(subjects, subject)
+			return stop
+		})
+		assert.Equal(t, stop, err)
+		assert.Equal(t, []string{"test1"}, subjects)
+	}
+}
+
+func TestSchemaRegistryClient_Ping(t *testing.T) {
+	t.Parallel()
+	var unavailable int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		switch req.URL.String() {
+		case "/":
+			if atomic.LoadInt32(&unavailable) == 1 {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			rw.Write([]byte(`{}`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+
+	{
+		err := srClient.Ping()
+		assert.NoError(t, err)
+	}
+	{
+		atomic.StoreInt32(&unavailable, 1)
+		err := srClient.PingWithContext(context.Background())
+		var registryErr Error
+		require.ErrorAs(t, err, &registryErr)
+		assert.Equal(t, http.StatusServiceUnavailable, registryErr.StatusCode)
+		assert.Contains(t, err.Error(), "failed to reach Schema Registry at "+server.URL)
+	}
+}
+
+func TestSchemaRegistryClient_GetMaxSchemaID(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		switch req.URL.String() {
+		case "/schemas?deleted=true":
+			rw.Write([]byte(`[{"subject":"test1-value","version":1,"id":4,"schema":"\"string\""},` +
+				`{"subject":"test2-value","version":1,"id":9,"schema":"\"int\""},` +
+				`{"subject":"test1-value","version":2,"id":6,"schema":"\"long\""}]`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	maxID, err := srClient.GetMaxSchemaID()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 9, maxID)
+}
+
+func TestSchemaRegistryClient_FindSubjectsWithSchema(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method)
+		switch req.URL.String() {
+		case "/schemas":
+			rw.Write([]byte(`[{"subject":"test1-value","version":1,"id":4,"schema":"{\"type\": \"string\"}"},` +
+				`{"subject":"test2-value","version":3,"id":4,"schema":"{\"type\":\"string\"}"},` +
+				`{"subject":"test3-value","version":1,"id":5,"schema":"{\"type\":\"int\"}"},` +
+				`{"subject":"test4-value","version":1,"id":6,"schemaType":"JSON","schema":"{\"type\":\"string\"}"}]`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	matches, err := srClient.FindSubjectsWithSchema("{\n  \"type\" : \"string\"\n}", Avro)
+
+	assert.NoError(t, err)
+	assert.Equal(t, SubjectVersionResponse{
+		{Subject: "test1-value", Version: 1},
+		{Subject: "test2-value", Version: 3},
+	}, matches)
+}
+
+func TestSchemaRegistryClient_GetSubjectsWithContext_DeadlineOverridesTimeout(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/subjects":
+			time.Sleep(100 * time.Millisecond)
+			rw.Write([]byte(`["test1-value"]`))
+		case "/schemas":
+			time.Sleep(100 * time.Millisecond)
+			rw.Write([]byte(`[]`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	srClient.SetTimeout(20 * time.Millisecond)
+
+	{
+		_, err := srClient.GetSubjects()
+		assert.Error(t, err)
+	}
+	{
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		subjects, err := srClient.GetSubjectsWithContext(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"test1-value"}, subjects)
+	}
+	{
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := srClient.GetAllSchemasWithContext(ctx, false, 0, 0)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+}
+
+func TestSchemaRegistryClient_GetSchemaWithContext_DeadlineOverridesTimeoutWithoutSingleflight(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "test2", ID: 1})
+		switch req.URL.String() {
+		case "/schemas/ids/1", "/subjects/test1/versions/latest", "/subjects/test1/versions/1":
+			rw.Write(response)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := NewSchemaRegistryClient(server.URL, WithoutSingleflight())
+	srClient.SetTimeout(20 * time.Millisecond)
+	srClient.CodecCreationEnabled(false)
+	srClient.CachingEnabled(false)
+
+	{
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		schema, err := srClient.GetSchemaWithContext(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "test2", schema.Schema())
+	}
+	{
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		schema, err := srClient.GetLatestSchemaWithContext(ctx, "test1")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, schema.Version())
+	}
+	{
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		schema, err := srClient.GetSchemaByVersionWithContext(ctx, "test1", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, schema.Version())
+	}
+	{
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := srClient.GetSchemaWithContext(ctx, 1)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := srClient.GetLatestSchemaWithContext(ctx, "test1")
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+func TestSchemaRegistryClient_GetSchemaWithContext_CancelDoesNotFailSharedCalls(t *testing.T) {
+	t.Parallel()
+	received := make(chan struct{}, 2)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		received <- struct{}{}
+		<-release
+		response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "test2", ID: 1})
+		switch req.URL.String() {
+		case "/schemas/ids/1":
+			rw.Write(response)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+	defer server.Close()
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	srClient.CodecCreationEnabled(false)
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancelledErr := make(chan error, 1)
+	go func() {
+		_, err := srClient.GetSchemaWithContext(cancelledCtx, 1)
+		cancelledErr <- err
+	}()
+	<-received
+
+	type result struct {
+		schema *Schema
+		err    error
+	}
+	waiting := make(chan result, 1)
+	go func() {
+		schema, err := srClient.GetSchemaWithContext(context.Background(), 1)
+		waiting <- result{schema, err}
+	}()
+	// Give the second call time to wait on the request made by the first one
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-cancelledErr, context.Canceled)
+
+	close(release)
+	got := <-waiting
+	assert.NoError(t, got.err)
+	assert.Equal(t, "test2", got.schema.Schema())
+	assert.Len(t, received, 0)
+}
+
+func TestSchemaRegistryClient_GetSubjectsByPrefix(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		includeDeleted bool
+		expectedURL    string
+	}{
+		"without deleted": {
+			includeDeleted: false,
+			expectedURL:    "/subjects?subjectPrefix=orders%2F",
+		},
+		"with deleted": {
+			includeDeleted: true,
+			expectedURL:    "/subjects?deleted=true&subjectPrefix=orders%2F",
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				switch req.URL.String() {
+				case testData.expectedURL:
+					rw.Write([]byte(`["orders/eu-value","orders/us-value"]`))
+				default:
+					require.Fail(t, "unhandled request")
+				}
+			}))
+
+			srClient := CreateSchemaRegistryClient(server.URL)
+			subjects, err := srClient.GetSubjectsByPrefix("orders/", testData.includeDeleted)
+
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"orders/eu-value", "orders/us-value"}, subjects)
+		})
+	}
+}
+
+func TestSchemaRegistryClient_GetSchemasByIDs(t *testing.T) {
+	t.Parallel()
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			previous := atomic.LoadInt32(&maxInFlight)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxInFlight, previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		if req.URL.String() == "/schemas/ids/4" {
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+			return
+		}
+		response, _ := json.Marshal(schemaResponse{Schema: req.URL.String()})
+		rw.Write(response)
+	}))
+
+	srClient := NewSchemaRegistryClient(server.URL, WithSemaphoreWeight(2))
+	schemas, err := srClient.GetSchemasByIDs([]int{1, 2, 3, 4, 1})
+
+	// Test the schemas that could be fetched are returned along with the failures
+	var schemasErr *SchemasByIDsError
+	if assert.True(t, errors.As(err, &schemasErr)) {
+		assert.Len(t, schemasErr.Errors, 1)
+		assert.ErrorIs(t, schemasErr.Errors[4], ErrSchemaNotFound)
+	}
+	require.Len(t, schemas, 3)
+	for _, schemaID := range []int{1, 2, 3} {
+		assert.Equal(t, fmt.Sprintf("/schemas/ids/%d", schemaID), schemas[schemaID].Schema())
+	}
+	// Test the requests are bounded by the semaphore weight
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+}
+
+func TestSchemaRegistryClient_GetCompatibilityLevels(t *testing.T) {
+	t.Parallel()
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			previous := atomic.LoadInt32(&maxInFlight)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxInFlight, previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		switch req.URL.String() {
+		case "/config/test1?defaultToGlobal=false", "/config/test2?defaultToGlobal=false":
+			rw.Write([]byte(`{"compatibilityLevel":"FULL"}`))
+		case "/config/test3?defaultToGlobal=false":
+			rw.Write([]byte(`{"compatibilityLevel":"NONE"}`))
+		case "/config/test4?defaultToGlobal=false":
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"error_code":40408,"message":"Subject 'test4' does not have subject-level compatibility configured"}`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := NewSchemaRegistryClient(server.URL, WithSemaphoreWeight(2))
+	levels, err := srClient.GetCompatibilityLevels([]string{"test1", "test2", "test3", "test4", "test1"}, false)
+
+	// Test the levels that could be fetched are returned along with the failures
+	var levelsErr *CompatibilityLevelsError
+	if assert.True(t, errors.As(err, &levelsErr)) {
+		assert.Len(t, levelsErr.Errors, 1)
+		assert.ErrorIs(t, levelsErr.Errors["test4"], ErrCompatibilityNotConfigured)
+	}
+	assert.Equal(t, map[string]CompatibilityLevel{"test1": Full, "test2": Full, "test3": None}, levels)
+	// Test the requests are bounded by the semaphore weight
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+}
+
+func TestSchemaRegistryClient_DeleteAllSubjects(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		permanent        bool
+		dryRun           bool
+		expectedDeletes  []string
+		expectedSubjects []string
+		expectedErrors   []string
+	}{
+		"dry run": {
+			dryRun:           true,
+			expectedSubjects: []string{"test1", "test2", "test3"},
+		},
+		"soft delete": {
+			expectedDeletes:  []string{"/subjects/test1", "/subjects/test2", "/subjects/test3"},
+			expectedSubjects: []string{"test1", "test3"},
+			expectedErrors:   []string{"test2"},
+		},
+		"permanent delete": {
+			permanent: true,
+			expectedDeletes: []string{
+				"/subjects/test1", "/subjects/test1?permanent=true",
+				"/subjects/test2",
+				"/subjects/test3", "/subjects/test3?permanent=true",
+			},
+			expectedSubjects: []string{"test1", "test3"},
+			expectedErrors:   []string{"test2"},
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var deletes []string
+			var deletesLock sync.Mutex
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if req.Method == http.MethodGet {
+					rw.Write([]byte(`["test3","test1","test2"]`))
+					return
+				}
+				deletesLock.Lock()
+				deletes = append(deletes, req.URL.String())
+				deletesLock.Unlock()
+				if strings.HasPrefix(req.URL.String(), "/subjects/test2") {
+					rw.WriteHeader(http.StatusInternalServerError)
+					rw.Write([]byte(`{"error_code":50001,"message":"Error in the backend data store"}`))
+					return
+				}
+				rw.Write([]byte("[1]"))
+			}))
+
+			srClient := CreateSchemaRegistryClient(server.URL)
+			subjects, err := srClient.DeleteAllSubjects(testData.permanent, testData.dryRun)
+
+			assert.Equal(t, testData.expectedSubjects, subjects)
+			assert.ElementsMatch(t, testData.expectedDeletes, deletes)
+			if len(testData.expectedErrors) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var deleteErr *DeleteSubjectsError
+			if assert.ErrorAs(t, err, &deleteErr) {
+				for _, subject := range testData.expectedErrors {
+					assert.Contains(t, deleteErr.Errors, subject)
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaRegistryClient_DeleteSchemaByID(t *testing.T) {
+	t.Parallel()
+	var deletes []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			switch req.URL.String() {
+			case "/schemas/ids/1/versions":
+				rw.Write([]byte(`[{"subject":"test1","version":1},{"subject":"test2","version":3}]`))
+			default:
+				require.Fail(t, "unhandled request")
+			}
+			return
+		}
+		deletes = append(deletes, req.URL.String())
+		if strings.HasPrefix(req.URL.String(), "/subjects/test2") {
+			rw.WriteHeader(http.StatusInternalServerError)
+			rw.Write([]byte(`{"error_code":50001,"message":"Error in the backend data store"}`))
+			return
+		}
+		rw.Write([]byte("1"))
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	err := srClient.DeleteSchemaByID(1, true)
+
+	assert.Equal(t, []string{
+		"/subjects/test1/versions/1", "/subjects/test1/versions/1?permanent=true",
+		"/subjects/test2/versions/3",
+	}, deletes)
+	var deleteErr *DeleteSchemaError
+	if assert.ErrorAs(t, err, &deleteErr) {
+		assert.Equal(t, 1, deleteErr.SchemaID)
+		assert.Len(t, deleteErr.Errors, 1)
+		assert.Contains(t, deleteErr.Errors, "test2/3")
+	}
+}
+
+func TestSchemaRegistryClient_CheckSchemaCompatibility(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/compatibility/subjects/test1/versions/latest?verbose=true":
+			rw.Write([]byte(`{"is_compatible":false,"messages":["reader field flavor missing default"]}`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	result, err := srClient.CheckSchemaCompatibility("test1", "test2", "latest", Avro)
+
+	assert.NoError(t, err)
+	assert.Equal(t, CompatibilityResult{
+		IsCompatible: false,
+		Messages:     []string{"reader field flavor missing default"},
+	}, result)
+}
+
+func TestSchemaRegistryClient_CheckSchemasCompatible(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/compatibility/subjects/test1/versions/1?verbose=true":
+			rw.Write([]byte(`{"is_compatible":true}`))
+		case "/compatibility/subjects/test1/versions/latest?verbose=true":
+			rw.Write([]byte(`{"is_compatible":false,"messages":["reader field flavor missing default"]}`))
+		case "/compatibility/subjects/test1/versions?verbose=true":
+			rw.WriteHeader(http.StatusUnprocessableEntity)
+			rw.Write([]byte(`{"error_code":42201,"message":"Invalid schema"}`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	results, err := srClient.CheckSchemasCompatible("test1", []SchemaCandidate{
+		{Schema: "test2", SchemaType: Avro, Version: "1"},
+		{Schema: "test3", SchemaType: Avro, Version: "latest"},
+		{Schema: "test4", SchemaType: Avro},
+	})
+
+	assert.Equal(t, []CompatibilityResult{
+		{IsCompatible: true},
+		{IsCompatible: false, Messages: []string{"reader field flavor missing default"}},
+		{},
+	}, results)
+	var checksErr *CompatibilityChecksError
+	if assert.ErrorAs(t, err, &checksErr) {
+		assert.Len(t, checksErr.Errors, 1)
+		assert.Contains(t, checksErr.Errors, 2)
+	}
+}
+
+func TestSchemaRegistryClient_IsSchemaCompatibleWithAll(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/compatibility/subjects/test1/versions":
+			rw.Write([]byte(`{"is_compatible":true}`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	isCompatible, err := srClient.IsSchemaCompatibleWithAll("test1", "test2", Avro)
+
+	assert.NoError(t, err)
+	assert.True(t, isCompatible)
+}
+
+func TestSchemaRegistryClient_GetSchemaTypes(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/schemas/types":
+			rw.Write([]byte(`["JSON","PROTOBUF","AVRO"]`))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+	schemaTypes, err := srClient.GetSchemaTypes()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []SchemaType{Json, Protobuf, Avro}, schemaTypes)
+}
+
+func TestSchemaRegistryClient_SchemaNewlineStripping(t *testing.T) {
+	t.Parallel()
+	const schema = "{\"type\": \"string\",\n\"doc\": \"first line\r\nsecond line\"}"
+	tests := map[string]struct {
+		options        []Option
+		expectedSchema string
+	}{
+		"stripped by default": {
+			options:        nil,
+			expectedSchema: "{\"type\": \"string\", \"doc\": \"first line second line\"}",
+		},
+		"kept without stripping": {
+			options:        []Option{WithoutSchemaNewlineStripping()},
+			expectedSchema: schema,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				switch req.URL.String() {
+				case "/subjects/test1":
+					var schemaReq schemaRequest
+					require.NoError(t, json.NewDecoder(req.Body).Decode(&schemaReq))
+					assert.Equal(t, testData.expectedSchema, schemaReq.Schema)
+					response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: schemaReq.Schema, ID: 1})
+					rw.Write(response)
+				default:
+					require.Fail(t, "unhandled request")
+				}
+			}))
+
+			srClient := NewSchemaRegistryClient(server.URL, testData.options...)
+			_, err := srClient.LookupSchema("test1", schema, Avro)
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSchemaRegistryClient_SchemaValidator(t *testing.T) {
+	t.Parallel()
+	errMissingDoc := errors.New("schema has no doc")
+	var validated []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/subjects/test1/versions":
+			rw.Write([]byte(`{"id":1}`))
+		case "/schemas/ids/1":
+			response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: `{"type": "string", "doc": "a"}`, ID: 1})
+			rw.Write(response)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := NewSchemaRegistryClient(server.URL, WithSchemaValidator(func(subject string, schema string, schemaType SchemaType) error {
+		validated = append(validated, schema)
+		if !strings.Contains(schema, `"doc"`) {
+			return errMissingDoc
+		}
+		return nil
+	}))
+
+	{
+		_, err := srClient.CreateSchema("test1", "{\"type\": \"string\",\n\"doc\": \"a\"}", Avro)
+		assert.NoError(t, err)
+	}
+	{
+		// The validator fails the calls before they reach the server
+		_, err := srClient.CreateSchema("test1", `"string"`, Avro)
+		assert.ErrorIs(t, err, errMissingDoc)
+		_, err = srClient.LookupSchema("test1", `"string"`, Avro)
+		assert.ErrorIs(t, err, errMissingDoc)
+	}
+	assert.Equal(t, []string{`{"type": "string", "doc": "a"}`, `"string"`, `"string"`}, validated)
+}
+
+func TestSchemaRegistryClient_Compression(t *testing.T) {
+	t.Parallel()
+	largeSchema := `{"type":"record","name":"cupcake","doc":"` + strings.Repeat("a", 2048) + `","fields":[]}`
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "gzip", req.Header.Get("Accept-Encoding"))
+		body := io.Reader(req.Body)
+		if req.Header.Get("Content-Encoding") == "gzip" {
+			gzipReader, err := gzip.NewReader(req.Body)
+			require.NoError(t, err)
+			body = gzipReader
+		}
+		var schemaReq schemaRequest
+		if req.Method == http.MethodPost {
+			require.NoError(t, json.NewDecoder(body).Decode(&schemaReq))
+		}
+
+		var response []byte
+		status := http.StatusOK
+		switch req.URL.String() {
+		case "/subjects/test1/versions":
+			assert.Equal(t, "gzip", req.Header.Get("Content-Encoding"))
+			assert.Equal(t, largeSchema, schemaReq.Schema)
+			response = []byte(`{"id":1}`)
+		case "/schemas/ids/1":
+			response, _ = json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: largeSchema, ID: 1})
+		case "/subjects/test1":
+			assert.Empty(t, req.Header.Get("Content-Encoding"))
+			status = http.StatusNotFound
+			response = []byte(`{"error_code":40403,"message":"Schema not found"}`)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+		rw.Header().Set("Content-Encoding", "gzip")
+		rw.WriteHeader(status)
+		gzipWriter := gzip.NewWriter(rw)
+		gzipWriter.Write(response)
+		gzipWriter.Close()
+	}))
+
+	srClient := NewSchemaRegistryClient(server.URL, WithCompression())
+
+	{
+		schema, err := srClient.CreateSchema("test1", largeSchema, Avro)
+		require.NoError(t, err)
+		assert.Equal(t, largeSchema, schema.Schema())
+	}
+	{
+		_, err := srClient.LookupSchema("test1", `"string"`, Avro)
+		assert.ErrorIs(t, err, ErrSchemaNotFound)
+		assert.Equal(t, `{"error_code":40403,"message":"Schema not found"}`, err.Error())
+	}
+}
+
+func TestSchemaRegistryClient_MaxResponseBodySize(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/subjects":
+			rw.Write([]byte(`["test1-value","test2-value"]`))
+		case "/subjects/test1-value/versions":
+			rw.Write([]byte(`[1,2,3,4,5,6,10]`))
+		case "/schemas/ids/1":
+			rw.WriteHeader(http.StatusInternalServerError)
+			rw.Write([]byte(strings.Repeat("a", 100)))
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := NewSchemaRegistryClient(server.URL, WithMaxResponseBodySize(16))
+
+	{
+		_, err := srClient.GetSubjects()
+		assert.ErrorIs(t, err, ErrResponseTooLarge)
+	}
+	{
+		// A body of the maximum size is still accepted
+		versions, err := srClient.GetSchemaVersions("test1-value")
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 10}, versions)
+	}
+	{
+		_, err := srClient.GetSchema(1)
+		var registryErr Error
+		require.ErrorAs(t, err, &registryErr)
+		assert.Equal(t, strings.Repeat("a", 16), registryErr.Body)
+	}
+	{
+		srClient := NewSchemaRegistryClient(server.URL, WithMaxResponseBodySize(0))
+		subjects, err := srClient.GetSubjects()
+		assert.NoError(t, err)
+		assert.Len(t, subjects, 2)
+	}
+}
+
+func TestSchemaRegistryClient_LookupSchemaNotFound(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		errorCode int
+	}{
+		"subject not found": {
+			errorCode: 40401,
+		},
+		"schema not found": {
+			errorCode: 40403,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(rw, `{"error_code":%d,"message":"not found"}`, testData.errorCode)
+			}))
+
+			srClient := CreateSchemaRegistryClient(server.URL)
+			schema, err := srClient.LookupSchema("test1", "test2", Avro)
+
+			assert.Nil(t, schema)
+			assert.ErrorIs(t, err, ErrSchemaNotFound)
+			var registryErr Error
+			if assert.ErrorAs(t, err, &registryErr) {
+				assert.Equal(t, testData.errorCode, registryErr.Code)
+			}
+		})
+	}
+}
+
+func TestSchemaRegistryClient_FindSchemaVersion(t *testing.T) {
+	t.Parallel()
+	{
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			switch req.URL.String() {
+			case "/subjects/test1":
+				response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 3, Schema: "test2", ID: 7})
+				rw.Write(response)
+			default:
+				require.Fail(t, "unhandled request")
+			}
+		}))
+
+		srClient := CreateSchemaRegistryClient(server.URL)
+		version, id, err := srClient.FindSchemaVersion("test1", "test2", Avro)
+
+		// Test response
+		assert.NoError(t, err)
+		assert.Equal(t, 3, version)
+		assert.Equal(t, 7, id)
+	}
+	{
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+		}))
+
+		srClient := CreateSchemaRegistryClient(server.URL)
+		_, _, err := srClient.FindSchemaVersion("test1", "test2", Avro)
+
+		// Test response
+		assert.ErrorIs(t, err, ErrSchemaNotFound)
+	}
+}
+
+func TestSchemaRegistryClient_NormalizesSchemas(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/subjects/test1/versions?normalize=true":
+			response, _ := json.Marshal(schemaResponse{ID: 1})
+			rw.Write(response)
+		case "/schemas/ids/1", "/subjects/test1?normalize=true":
+			response, _ := json.Marshal(schemaResponse{Subject: "test1", Version: 1, Schema: "payload", ID: 1})
+			rw.Write(response)
+		default:
+			require.Fail(t, "unhandled request")
+		}
+	}))
+
+	srClient := CreateSchemaRegistryClient(server.URL)
+
+	created, err := srClient.CreateSchemaNormalized("test1", "payload", Avro)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created.ID())
+
+	srClient.ResetCache()
+	found, err := srClient.LookupSchemaNormalized("test1", "payload", Avro)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, found.ID())
+}
+
+func TestSchemaRegistryClient_ErrorsPreserveResponse(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		statusCode    int
+		body          string
+		retryAfter    string
+		expectedError Error
+		expectedText  string
+	}{
+		"registry error": {
+			statusCode: http.StatusNotFound,
+			body:       `{"error_code":40403,"message":"Schema not found"}`,
+			expectedError: Error{
+				Code:       40403,
+				Message:    "Schema not found",
+				StatusCode: http.StatusNotFound,
+				Body:       `{"error_code":40403,"message":"Schema not found"}`,
+			},
+			expectedText: `{"error_code":40403,"message":"Schema not found"}`,
+		},
+		"proxy error": {
+			statusCode: http.StatusBadGateway,
+			body:       "<html>Bad Gateway</html>",
+			expectedError: Error{
+				StatusCode: http.StatusBadGateway,
+				Body:       "<html>Bad Gateway</html>",
+			},
+			expectedText: "502 Bad Gateway: <html>Bad Gateway</html>",
+		},
+		"throttled": {
+			statusCode: http.StatusTooManyRequests,
+			body:       `{"error_code":42901,"message":"Too many requests"}`,
+			retryAfter: "30",
+			expectedError: Error{
+				Code:       42901,
+				Message:    "Too many requests",
+				StatusCode: http.StatusTooManyRequests,
+				Body:       `{"error_code":42901,"message":"Too many requests"}`,
+				RetryAfter: "30",
+			},
+			expectedText: `{"error_code":42901,"message":"Too many requests"}`,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if testData.retryAfter != "" {
+					rw.Header().Set("Retry-After", testData.retryAfter)
+				}
+				rw.WriteHeader(testData.statusCode)
+				rw.Write([]byte(testData.body))
+			}))
+
+			srClient := CreateSchemaRegistryClient(server.URL)
+			_, err := srClient.GetSchema(1)
+
+			var registryErr Error
+			if assert.True(t, errors.As(err, &registryErr)) {
+				assert.Equal(t, testData.expectedError, registryErr)
+			}
+			assert.EqualError(t, err, testData.expectedText)
+		})
+	}
+}
+
+func TestSchemaRegistryClient_ErrorsMatchSentinels(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		statusCode    int
+		errorCode     int
+		expectedError error
+	}{
+		"subject not found": {
+			statusCode:    http.StatusNotFound,
+			errorCode:     40401,
+			expectedError: ErrSubjectNotFound,
+		},
+		"version not found": {
+			statusCode:    http.StatusNotFound,
+			errorCode:     40402,
+			expectedError: ErrVersionNotFound,
+		},
+		"schema not found": {
+			statusCode:    http.StatusNotFound,
+			errorCode:     40403,
+			expectedError: ErrSchemaNotFound,
+		},
+		"version soft deleted": {
+			statusCode:    http.StatusNotFound,
+			errorCode:     40406,
+			expectedError: ErrVersionSoftDeleted,
+		},
+		"incompatible schema": {
+			statusCode:    http.StatusConflict,
+			errorCode:     409,
+			expectedError: ErrIncompatibleSchema,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(testData.statusCode)
+				fmt.Fprintf(rw, `{"error_code":%d,"message":"failed"}`, testData.errorCode)
+			}))
+
+			srClient := CreateSchemaRegistryClient(server.URL)
+			_, err := srClient.GetSchema(1)
+
+			assert.ErrorIs(t, err, testData.expectedError)
+			for _, sentinel := range registryErrors {
+				if sentinel != testData.expectedError {
+					assert.NotErrorIs(t, err, sentinel)
+				}
+			}
+			var registryErr Error
+			if assert.True(t, errors.As(err, &registryErr)) {
+				assert.Equal(t, testData.expectedError, errors.Unwrap(registryErr))
+				assert.Equal(t, testData.statusCode, registryErr.HTTPStatusCode())
+				assert.Equal(t, `{"error_code":`+strconv.Itoa(testData.errorCode)+`,"message":"failed"}`, registryErr.Error())
+			}
+		})
+	}
+}
+
+func TestError_UnwrapsNothingWithoutSentinel(t *testing.T) {
+	t.Parallel()
+	err := Error{Code: 50001, Message: "Error in the backend data store", StatusCode: http.StatusInternalServerError}
+
+	assert.Nil(t, errors.Unwrap(err))
+	assert.Equal(t, http.StatusInternalServerError, err.HTTPStatusCode())
+}
+
+func TestSchemaRegistryClient_DoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+	var count int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		count++
+		rw.WriteHeader(http.StatusNotFound)
+		rw.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+	}))
+
+	srClient := NewSchemaRegistryClient(server.URL, WithRetry(3, time.Millisecond))
+	_, err := srClient.GetSchema(1)
+
+	// Test response is returned as is
+	assert.Equal(t, 1, count)
+	castedErr, ok := err.(Error)
+	assert.True(t, ok, "convert api error to Error struct")
+	assert.Equal(t, 40403, castedErr.Code)
+}
+
+func TestSchemaRegistryClient_RetriesPostOnlyWhenEnabled(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		options       []Option
+		expectedCalls int
+	}{
+		"no retry": {
+			expectedCalls: 1,
+		},
+		"retry without post": {
+			options:       []Option{WithRetry(2, time.Millisecond)},
+			expectedCalls: 1,
+		},
+		"retry with post": {
+			options:       []Option{WithRetry(2, time.Millisecond), WithRetryOnPost(true)},
+			expectedCalls: 3,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var count int
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				count++
+				// The payload must be sent on every attempt
+				assert.NotEmpty(t, bodyToString(req.Body))
+				rw.WriteHeader(http.StatusServiceUnavailable)
+			}))
+
+			srClient := NewSchemaRegistryClient(server.URL, testData.options...)
+			_, err := srClient.LookupSchema("test1", "test2", Avro)
+
+			assert.Error(t, err)
+			assert.Equal(t, testData.expectedCalls, count)
+		})
+	}
+}
+
 func mockServerFromSubjectVersionPairWithSchemaResponse(t *testing.T, subject, version string, schemaResponse schemaResponse) (*httptest.Server, *int) {
 	return mockServerWithSchemaResponse(t, fmt.Sprintf("/subjects/%s/versions/%s", subject, version), schemaResponse)
 }
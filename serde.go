@@ -0,0 +1,248 @@
+package srclient
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// defaultMagicByte is the first byte of every record framed
+// with the wire format used by Confluent's serializers.
+const defaultMagicByte byte = 0
+
+// wireHeaderSize is the size of the magic byte
+// followed by the 4-byte big-endian schema ID.
+const wireHeaderSize = 5
+
+var (
+	// ErrInvalidMagicByte is returned when a record doesn't start with the expected magic byte.
+	ErrInvalidMagicByte = errors.New("invalid magic byte")
+	// ErrMessageTooShort is returned when a record is too short to hold the wire format header.
+	ErrMessageTooShort = errors.New("message is too short to hold the wire format header")
+	// ErrMissingRecordName is returned by the strategies that name subjects after
+	// the record when no record name is given.
+	ErrMissingRecordName = errors.New("the subject name strategy requires a record name")
+)
+
+// SerdeType tells whether a serializer or deserializer
+// handles the keys or the values of records.
+type SerdeType int
+
+const (
+	KeySerde SerdeType = iota
+	ValueSerde
+)
+
+// SchemaResolver resolves the schema that
+// serializers use to encode the records
+// produced to a topic.
+type SchemaResolver interface {
+	ResolveSchema(topic string) (*Schema, error)
+}
+
+// SubjectNameStrategy computes the subject under which
+// the schema of the records produced to a topic is registered.
+type SubjectNameStrategy interface {
+	SubjectName(topic string, recordName string, serdeType SerdeType) (string, error)
+}
+
+// TopicNameStrategy names subjects after the topic, suffixed
+// by "-key" or "-value" depending on the SerdeType.
+type TopicNameStrategy struct{}
+
+// RecordNameStrategy names subjects after the fully-qualified
+// record name, so a record has the same subject in every topic.
+type RecordNameStrategy struct{}
+
+// TopicRecordNameStrategy names subjects after the topic and
+// the fully-qualified record name, separated by a dash.
+type TopicRecordNameStrategy struct{}
+
+var (
+	_ SubjectNameStrategy = TopicNameStrategy{}
+	_ SubjectNameStrategy = RecordNameStrategy{}
+	_ SubjectNameStrategy = TopicRecordNameStrategy{}
+)
+
+// SubjectName returns the topic's key or value subject.
+func (TopicNameStrategy) SubjectName(topic string, _ string, serdeType SerdeType) (string, error) {
+	if serdeType == KeySerde {
+		return topic + "-key", nil
+	}
+	return topic + "-value", nil
+}
+
+// SubjectName returns the record name.
+func (RecordNameStrategy) SubjectName(_ string, recordName string, _ SerdeType) (string, error) {
+	if recordName == "" {
+		return "", ErrMissingRecordName
+	}
+	return recordName, nil
+}
+
+// SubjectName returns the topic and record name.
+func (TopicRecordNameStrategy) SubjectName(topic string, recordName string, _ SerdeType) (string, error) {
+	if recordName == "" {
+		return "", ErrMissingRecordName
+	}
+	return topic + "-" + recordName, nil
+}
+
+// SubjectNameSchemaResolver resolves the latest schema
+// registered under the subject computed by its strategy.
+type SubjectNameSchemaResolver struct {
+	client     ISchemaRegistryClient
+	strategy   SubjectNameStrategy
+	recordName string
+	serdeType  SerdeType
+}
+
+var _ SchemaResolver = new(SubjectNameSchemaResolver)
+
+// NewSubjectNameSchemaResolver creates a resolver that looks up the latest schema of
+// the subject the strategy computes for the topic and record name. The record name
+// is only required by the strategies that name subjects after the record.
+func NewSubjectNameSchemaResolver(client ISchemaRegistryClient, strategy SubjectNameStrategy,
+	recordName string, serdeType SerdeType) *SubjectNameSchemaResolver {
+	return &SubjectNameSchemaResolver{
+		client:     client,
+		strategy:   strategy,
+		recordName: recordName,
+		serdeType:  serdeType,
+	}
+}
+
+// ResolveSchema returns the latest schema of the subject computed for the topic.
+// A missing subject returns an error matching ErrSubjectNotFound, even when the
+// client is created with WithNotFoundAsNil.
+func (resolver *SubjectNameSchemaResolver) ResolveSchema(topic string) (*Schema, error) {
+	subject, err := resolver.strategy.SubjectName(topic, resolver.recordName, resolver.serdeType)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := resolver.client.GetLatestSchema(subject)
+	if err == nil && schema == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSubjectNotFound, subject)
+	}
+	return schema, err
+}
+
+// RecordNameSchemaResolver resolves the schema of the records it's created with,
+// registering it under the subject its strategy computes from the topic and the
+// full name of the Avro record, unless it's already registered there.
+type RecordNameSchemaResolver struct {
+	client      ISchemaRegistryClient
+	strategy    SubjectNameStrategy
+	schema      string
+	recordName  string
+	serdeType   SerdeType
+	schemas     map[string]*Schema
+	schemasLock sync.Mutex
+}
+
+var _ SchemaResolver = new(RecordNameSchemaResolver)
+
+// NewRecordNameSchemaResolver creates a resolver for records with the given Avro schema,
+// whose record name is read from its name and namespace. It returns an error if the
+// schema isn't an Avro named type.
+func NewRecordNameSchemaResolver(client ISchemaRegistryClient, strategy SubjectNameStrategy,
+	schema string, serdeType SerdeType) (*RecordNameSchemaResolver, error) {
+	recordName, err := avroRecordName(schema)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordNameSchemaResolver{
+		client:     client,
+		strategy:   strategy,
+		schema:     schema,
+		recordName: recordName,
+		serdeType:  serdeType,
+		schemas:    make(map[string]*Schema),
+	}, nil
+}
+
+// RecordName returns the full name of the record the resolver was created with.
+func (resolver *RecordNameSchemaResolver) RecordName() string {
+	return resolver.recordName
+}
+
+// ResolveSchema returns the schema as registered under the subject computed for the topic.
+func (resolver *RecordNameSchemaResolver) ResolveSchema(topic string) (*Schema, error) {
+	subject, err := resolver.strategy.SubjectName(topic, resolver.recordName, resolver.serdeType)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver.schemasLock.Lock()
+	defer resolver.schemasLock.Unlock()
+	if schema, ok := resolver.schemas[subject]; ok {
+		return schema, nil
+	}
+
+	var schema *Schema
+	version, _, err := resolver.client.FindSchemaVersion(subject, resolver.schema, Avro)
+	switch {
+	case errors.Is(err, ErrSchemaNotFound):
+		schema, err = resolver.client.CreateSchema(subject, resolver.schema, Avro)
+	case err == nil:
+		schema, err = resolver.client.GetSchemaByVersion(subject, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resolver.schemas[subject] = schema
+	return schema, nil
+}
+
+// avroRecordName returns the full name of the named type defined by the Avro schema.
+func avroRecordName(schema string) (string, error) {
+	var namedType map[string]interface{}
+	if err := json.Unmarshal([]byte(schema), &namedType); err != nil {
+		return "", fmt.Errorf("failed to read the record name of the schema: %w", err)
+	}
+	if name, _ := namedType["name"].(string); name == "" {
+		return "", errors.New("failed to read the record name of the schema: it has no name")
+	}
+	fullName, _ := avroName(namedType, "")
+	return fullName, nil
+}
+
+// TopicNameSchemaResolver resolves the latest schema
+// registered under the subject named after the topic,
+// suffixed by "-key" or "-value" depending on the SerdeType.
+type TopicNameSchemaResolver struct {
+	SubjectNameSchemaResolver
+}
+
+var _ SchemaResolver = new(TopicNameSchemaResolver)
+
+// NewTopicNameSchemaResolver creates a resolver that looks up
+// the latest schema of the topic's key or value subject.
+func NewTopicNameSchemaResolver(client ISchemaRegistryClient, serdeType SerdeType) *TopicNameSchemaResolver {
+	return &TopicNameSchemaResolver{
+		SubjectNameSchemaResolver: *NewSubjectNameSchemaResolver(client, TopicNameStrategy{}, "", serdeType),
+	}
+}
+
+// encodeHeader returns the wire format header for the given magic byte and schema ID.
+func encodeHeader(magicByte byte, schemaID int) []byte {
+	header := make([]byte, wireHeaderSize)
+	header[0] = magicByte
+	binary.BigEndian.PutUint32(header[1:wireHeaderSize], uint32(schemaID))
+	return header
+}
+
+// decodeHeader returns the schema ID and payload of a record framed with the wire
+// format, making sure it starts with the given magic byte.
+func decodeHeader(magicByte byte, data []byte) (int, []byte, error) {
+	if len(data) < wireHeaderSize {
+		return 0, nil, ErrMessageTooShort
+	}
+	if data[0] != magicByte {
+		return 0, nil, fmt.Errorf("%w: expected %d, got %d", ErrInvalidMagicByte, magicByte, data[0])
+	}
+	schemaID := int(binary.BigEndian.Uint32(data[1:wireHeaderSize]))
+	return schemaID, data[wireHeaderSize:], nil
+}
@@ -0,0 +1,170 @@
+package srclient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubjectNameStrategies(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		strategy        SubjectNameStrategy
+		recordName      string
+		serdeType       SerdeType
+		expectedSubject string
+		expectedError   error
+	}{
+		"topic name for values": {
+			strategy:        TopicNameStrategy{},
+			recordName:      "com.example.Cupcake",
+			serdeType:       ValueSerde,
+			expectedSubject: "cupcakes-value",
+		},
+		"topic name for keys": {
+			strategy:        TopicNameStrategy{},
+			serdeType:       KeySerde,
+			expectedSubject: "cupcakes-key",
+		},
+		"record name": {
+			strategy:        RecordNameStrategy{},
+			recordName:      "com.example.Cupcake",
+			serdeType:       ValueSerde,
+			expectedSubject: "com.example.Cupcake",
+		},
+		"record name without record": {
+			strategy:      RecordNameStrategy{},
+			serdeType:     ValueSerde,
+			expectedError: ErrMissingRecordName,
+		},
+		"topic record name": {
+			strategy:        TopicRecordNameStrategy{},
+			recordName:      "com.example.Cupcake",
+			serdeType:       KeySerde,
+			expectedSubject: "cupcakes-com.example.Cupcake",
+		},
+		"topic record name without record": {
+			strategy:      TopicRecordNameStrategy{},
+			serdeType:     ValueSerde,
+			expectedError: ErrMissingRecordName,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			subject, err := testData.strategy.SubjectName("cupcakes", testData.recordName, testData.serdeType)
+
+			assert.Equal(t, testData.expectedSubject, subject)
+			assert.ErrorIs(t, err, testData.expectedError)
+		})
+	}
+}
+
+func TestSubjectNameSchemaResolver_ResolvesRecordSubject(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	schema, err := registry.CreateSchema("cupcakes-cupcake", testSchema1, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver := NewSubjectNameSchemaResolver(registry, TopicRecordNameStrategy{}, "cupcake", ValueSerde)
+
+	// Act
+	resolved, err := resolver.ResolveSchema("cupcakes")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, schema, resolved)
+}
+
+func TestAvroRecordName(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		schema       string
+		expectedName string
+		expectError  bool
+	}{
+		"namespace and name": {
+			schema:       `{"type":"record","name":"Cupcake","namespace":"com.bakery","fields":[]}`,
+			expectedName: "com.bakery.Cupcake",
+		},
+		"full name": {
+			schema:       `{"type":"record","name":"com.bakery.Cupcake","namespace":"ignored","fields":[]}`,
+			expectedName: "com.bakery.Cupcake",
+		},
+		"without namespace": {
+			schema:       `{"type":"enum","name":"Flavor","symbols":["VANILLA"]}`,
+			expectedName: "Flavor",
+		},
+		"primitive": {
+			schema:      `"string"`,
+			expectError: true,
+		},
+		"without name": {
+			schema:      `{"type":"array","items":"string"}`,
+			expectError: true,
+		},
+	}
+
+	for name, testData := range tests {
+		testData := testData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			recordName, err := avroRecordName(testData.schema)
+
+			assert.Equal(t, testData.expectedName, recordName)
+			assert.Equal(t, testData.expectError, err != nil)
+		})
+	}
+}
+
+func TestRecordNameSchemaResolver_RegistersUnderRecordSubject(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	schema := `{"type":"record","name":"Cupcake","namespace":"com.bakery","fields":[{"name":"flavor","type":"string"}]}`
+	resolver, err := NewRecordNameSchemaResolver(registry, RecordNameStrategy{}, schema, ValueSerde)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	registered, err := resolver.ResolveSchema("cupcakes")
+	assert.NoError(t, err)
+	resolved, err := resolver.ResolveSchema("muffins")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "com.bakery.Cupcake", resolver.RecordName())
+	assert.Equal(t, registered, resolved)
+	latest, err := registry.GetLatestSchema("com.bakery.Cupcake")
+	assert.NoError(t, err)
+	assert.Equal(t, registered.ID(), latest.ID())
+}
+
+func TestRecordNameSchemaResolver_FindsRegisteredSchema(t *testing.T) {
+	t.Parallel()
+	// Arrange
+	registry := CreateMockSchemaRegistryClient("http://localhost:8081")
+	schema := `{"type":"record","name":"Cupcake","namespace":"com.bakery","fields":[{"name":"flavor","type":"string"}]}`
+	registered, err := registry.CreateSchema("cupcakes-value", schema, Avro)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver, err := NewRecordNameSchemaResolver(registry, TopicNameStrategy{}, schema, ValueSerde)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	resolved, err := resolver.ResolveSchema("cupcakes")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, registered, resolved)
+}